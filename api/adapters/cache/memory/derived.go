@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+type derivedEntry struct {
+	key  string
+	data []byte
+}
+
+// derivedLRUCache evicts least recently used images once total size exceeds maxBytes
+type derivedLRUCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+}
+
+func NewDerivedCacheMemory(maxBytes int64) clients.DerivedCache {
+	return &derivedLRUCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *derivedLRUCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, customErrors.ErrCacheMiss
+	}
+
+	c.order.MoveToFront(element)
+
+	return element.Value.(*derivedEntry).data, nil
+}
+
+func (c *derivedLRUCache) Set(_ context.Context, key string, data []byte) error {
+	// image which doesn't fit would evict whole cache and be dropped itself
+	if int64(len(data)) > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*derivedEntry)
+		c.size += int64(len(data) - len(entry.data))
+		entry.data = data
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(&derivedEntry{key: key, data: data})
+		c.size += int64(len(data))
+	}
+
+	for c.size > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*derivedEntry)
+
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.data))
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/clients/postgresql"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+var ErrNoBackgroundFound = customErrors.ErrBackgroundNotFound
+
+type backgroundPgStorage struct {
+	client postgresql.Client
+	logger *zerolog.Logger
+}
+
+func NewBackgroundStoragePG(client postgresql.Client, logger *zerolog.Logger) clients.BackgroundStorage {
+	return &backgroundPgStorage{
+		client: client,
+		logger: logger,
+	}
+}
+
+func (p backgroundPgStorage) Create(ctx context.Context, background *dtos.Background) error {
+	query := `
+		INSERT INTO service.backgrounds
+		    (
+		     name,
+		     mime_type,
+		     width,
+		     height,
+		     data
+		     )
+		VALUES
+		       ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	if err := p.client.QueryRow(ctx, query,
+		background.Name,
+		background.MimeType,
+		background.Width,
+		background.Height,
+		background.Data,
+	).Scan(&background.ID, &background.CreatedAt); err != nil {
+		return fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	p.logger.Info().Msgf("background created with id: %v", background.ID)
+
+	return nil
+}
+
+func (p backgroundPgStorage) FindAll(ctx context.Context) ([]dtos.Background, error) {
+	query := `
+		SELECT id,
+		       name,
+		       mime_type,
+		       width,
+		       height,
+		       created_at
+		FROM service.backgrounds
+		WHERE NOT is_deleted
+		ORDER BY created_at DESC;
+	`
+
+	backgrounds := make([]dtos.Background, 0)
+
+	rows, err := p.client.Query(ctx, query)
+	if err != nil {
+		return backgrounds, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var background dtos.Background
+
+		if err = rows.Scan(
+			&background.ID,
+			&background.Name,
+			&background.MimeType,
+			&background.Width,
+			&background.Height,
+			&background.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		backgrounds = append(backgrounds, background)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return backgrounds, nil
+}
+
+func (p backgroundPgStorage) FindOne(ctx context.Context, id string) (dtos.Background, error) {
+	query := `
+		SELECT id,
+		       name,
+		       mime_type,
+		       width,
+		       height,
+		       data,
+		       created_at,
+		       is_deleted
+		FROM service.backgrounds
+		WHERE id = $1;
+	`
+
+	var background dtos.Background
+
+	if err := p.client.QueryRow(ctx, query, id).Scan(
+		&background.ID,
+		&background.Name,
+		&background.MimeType,
+		&background.Width,
+		&background.Height,
+		&background.Data,
+		&background.CreatedAt,
+		&background.IsDeleted,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.Background{}, ErrNoBackgroundFound
+		}
+
+		return dtos.Background{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return background, nil
+}
+
+// Delete hides background from library, FindOne still returns it for photos using it
+func (p backgroundPgStorage) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE service.backgrounds
+		   SET is_deleted = TRUE
+		 WHERE id = $1 AND NOT is_deleted;
+	`
+
+	commandTag, err := p.client.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("client.Exec() failed: %w", err)
+	}
+	if commandTag.RowsAffected() != 1 {
+		return customErrors.ErrNoRowsFindToDelete
+	}
+
+	p.logger.Debug().Msgf("background with id = %s DELETED", id)
+
+	return nil
+}
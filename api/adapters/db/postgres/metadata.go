@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+var photoSortColumns = map[string]string{
+	entities.PhotoSortCapturedAt:  "m.captured_at",
+	entities.PhotoSortCameraMake:  "m.camera_make",
+	entities.PhotoSortCameraModel: "m.camera_model",
+	entities.PhotoSortISO:         "m.iso",
+	entities.PhotoSortFocalLength: "m.focal_length",
+}
+
+func insertMetadata(ctx context.Context, tx pgx.Tx, metadata *dtos.PhotoMetadata) error {
+	query := `
+		INSERT INTO service.photo_metadata
+		    (
+		     photo_id,
+		     width,
+		     height,
+		     captured_at,
+		     camera_make,
+		     camera_model,
+		     lens,
+		     exposure_time,
+		     f_number,
+		     iso,
+		     focal_length,
+		     gps_latitude,
+		     gps_longitude,
+		     color_space
+		     )
+		VALUES
+		       ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	if _, err := tx.Exec(ctx, query,
+		metadata.PhotoID,
+		metadata.Width,
+		metadata.Height,
+		metadata.CapturedAt,
+		metadata.CameraMake,
+		metadata.CameraModel,
+		metadata.Lens,
+		metadata.ExposureTime,
+		metadata.FNumber,
+		metadata.ISO,
+		metadata.FocalLength,
+		metadata.GPSLatitude,
+		metadata.GPSLongitude,
+		metadata.ColorSpace,
+	); err != nil {
+		return fmt.Errorf("tx.Exec() failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p photoPgStorage) FindMetadata(ctx context.Context, id string) (dtos.PhotoMetadata, error) {
+	query := `
+		SELECT photo_id,
+		       width,
+		       height,
+		       captured_at,
+		       camera_make,
+		       camera_model,
+		       lens,
+		       exposure_time,
+		       f_number,
+		       iso,
+		       focal_length,
+		       gps_latitude,
+		       gps_longitude,
+		       color_space
+		FROM service.photo_metadata
+		WHERE photo_id = $1;
+	`
+
+	var metadata dtos.PhotoMetadata
+	err := p.client.QueryRow(ctx, query, id).Scan(
+		&metadata.PhotoID,
+		&metadata.Width,
+		&metadata.Height,
+		&metadata.CapturedAt,
+		&metadata.CameraMake,
+		&metadata.CameraModel,
+		&metadata.Lens,
+		&metadata.ExposureTime,
+		&metadata.FNumber,
+		&metadata.ISO,
+		&metadata.FocalLength,
+		&metadata.GPSLatitude,
+		&metadata.GPSLongitude,
+		&metadata.ColorSpace,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.PhotoMetadata{}, ErrNoPhotoFound
+		}
+
+		return dtos.PhotoMetadata{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// photoFilterSQL builds WHERE and ORDER BY clauses of photos listing, metadata is joined as "m"
+func photoFilterSQL(filter dtos.PhotoFilter) (string, string, []any) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CameraMake != "" {
+		addCondition("m.camera_make ILIKE $%d", filter.CameraMake)
+	}
+
+	if filter.CameraModel != "" {
+		addCondition("m.camera_model ILIKE $%d", filter.CameraModel)
+	}
+
+	if filter.CapturedFrom != nil {
+		addCondition("m.captured_at >= $%d", *filter.CapturedFrom)
+	}
+
+	if filter.CapturedTo != nil {
+		addCondition("m.captured_at <= $%d", *filter.CapturedTo)
+	}
+
+	if filter.HasGPS != nil {
+		if *filter.HasGPS {
+			conditions = append(conditions, "m.gps_latitude IS NOT NULL")
+		} else {
+			conditions = append(conditions, "m.gps_latitude IS NULL")
+		}
+	}
+
+	if filter.HideFlagged {
+		conditions = append(conditions, "cardinality(p.quality_flags) = 0")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := ""
+	if column, ok := photoSortColumns[filter.SortBy]; ok {
+		direction := "ASC"
+		if filter.SortDesc {
+			direction = "DESC"
+		}
+
+		orderBy = fmt.Sprintf(" ORDER BY %s %s NULLS LAST, p.id", column, direction)
+	}
+
+	return where, orderBy, args
+}
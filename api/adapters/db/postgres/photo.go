@@ -34,6 +34,17 @@ type PhotoPG struct {
 	Data50     null.String `json:"data50"`     // Stored in b64
 	Data25     null.String `json:"data25"`     // Stored in b64
 	IsDeleted  null.Bool   `json:"isDeleted"`
+
+	RemovedExifTags []string    `json:"removedExifTags"`
+	PHash           null.Int    `json:"phash"`
+	DuplicateOf     null.String `json:"duplicateOf"`
+	BlurHash        null.String `json:"blurhash"`
+	Palette         []string    `json:"palette"`
+
+	Options dtos.ProcessingOptions `json:"options"`
+
+	QualityScores *dtos.QualityScores `json:"qualityScores"`
+	QualityFlags  []string            `json:"qualityFlags"`
 }
 
 func (p photoPgStorage) Create(ctx context.Context, photo *dtos.PhotoDB) error {
@@ -44,21 +55,67 @@ func (p photoPgStorage) Create(ctx context.Context, photo *dtos.PhotoDB) error {
 		     data_75,
 		     data_50,
 		     data_25,
-		     is_deleted
+		     is_deleted,
+		     removed_exif_tags,
+		     phash,
+		     phash_bands,
+		     duplicate_of,
+		     blurhash,
+		     palette,
+		     options,
+		     quality_scores,
+		     quality_flags
 		     )
 		VALUES
-		       ($1, $2, $3, $4, $5)
+		       ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
-	if err := p.client.QueryRow(ctx, query,
+	phash, phashBands := phashColumns(photo.PerceptualHash)
+
+	tx, err := p.client.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("client.Begin() failed: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			p.logger.Error().Err(err).Msg("tx.Rollback() failed")
+		}
+	}()
+
+	if err := tx.QueryRow(ctx, query,
 		photo.DataOrigin,
 		photo.Data75,
 		photo.Data50,
 		photo.Data25,
 		photo.IsDeleted,
+		photo.RemovedExifTags,
+		phash,
+		phashBands,
+		null.NewString(photo.DuplicateOf, photo.DuplicateOf != ""),
+		photo.BlurHash,
+		photoPalette(photo.Palette),
+		photo.Options,
+		photo.QualityScores,
+		photoQualityFlags(photo.QualityFlags),
 	).Scan(&photo.ID); err != nil {
-		return fmt.Errorf("client.QueryRow() failed: %w", err)
+		return fmt.Errorf("tx.QueryRow() failed: %w", err)
+	}
+
+	if photo.Metadata != nil {
+		photo.Metadata.PhotoID = photo.ID
+
+		if err := insertMetadata(ctx, tx, photo.Metadata); err != nil {
+			return fmt.Errorf("insertMetadata() failed: %w", err)
+		}
+	}
+
+	if err := insertVariants(ctx, tx, photo.ID, photo.Variants); err != nil {
+		return fmt.Errorf("insertVariants() failed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit() failed: %w", err)
 	}
 
 	p.logger.Info().Msgf("photo created with id: %v", photo.ID)
@@ -66,22 +123,49 @@ func (p photoPgStorage) Create(ctx context.Context, photo *dtos.PhotoDB) error {
 	return nil
 }
 
-var ErrNoPhotoFound = errors.New("didn't find photo")
+// photoPalette keeps palette column NOT NULL for photos without palette
+func photoPalette(palette []string) []string {
+	if palette == nil {
+		return make([]string, 0)
+	}
+
+	return palette
+}
+
+// photoQualityFlags keeps quality_flags column NOT NULL for photos without flags
+func photoQualityFlags(flags []string) []string {
+	if flags == nil {
+		return make([]string, 0)
+	}
+
+	return flags
+}
 
-func (p photoPgStorage) FindAll(ctx context.Context) ([]dtos.PhotoDB, error) {
+var ErrNoPhotoFound = customErrors.ErrPhotoNotFound
+
+func (p photoPgStorage) FindAll(ctx context.Context, filter dtos.PhotoFilter) ([]dtos.PhotoDB, error) {
 	query := `
-		SELECT id, 
-		       data_origin, 
-		       data_75,
-		       data_50,
-		       data_25,
-		       is_deleted
-		FROM service.photos;
+		SELECT p.id, 
+		       p.data_origin, 
+		       p.data_75,
+		       p.data_50,
+		       p.data_25,
+		       p.is_deleted,
+		       p.removed_exif_tags,
+		       p.blurhash,
+		       p.palette,
+		       p.quality_scores,
+		       p.quality_flags
+		FROM service.photos p
+		LEFT JOIN service.photo_metadata m ON m.photo_id = p.id
 	`
 
+	where, orderBy, args := photoFilterSQL(filter)
+	query += where + orderBy
+
 	photosList := make([]dtos.PhotoDB, 0)
 
-	rows, err := p.client.Query(ctx, query)
+	rows, err := p.client.Query(ctx, query, args...)
 	if err != nil {
 		return photosList, fmt.Errorf("client.Query() failed: %w", err)
 	}
@@ -97,6 +181,11 @@ func (p photoPgStorage) FindAll(ctx context.Context) ([]dtos.PhotoDB, error) {
 			&photoPG.Data50,
 			&photoPG.Data25,
 			&photoPG.IsDeleted,
+			&photoPG.RemovedExifTags,
+			&photoPG.BlurHash,
+			&photoPG.Palette,
+			&photoPG.QualityScores,
+			&photoPG.QualityFlags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("client.Query() failed: %w", err)
@@ -108,7 +197,13 @@ func (p photoPgStorage) FindAll(ctx context.Context) ([]dtos.PhotoDB, error) {
 			Data75:     photoPG.Data75.String,
 			Data50:     photoPG.Data50.String,
 			Data25:     photoPG.Data25.String,
-			IsDeleted:  false,
+			IsDeleted:  photoPG.IsDeleted.Bool,
+
+			RemovedExifTags: photoPG.RemovedExifTags,
+			BlurHash:        photoPG.BlurHash.String,
+			Palette:         photoPG.Palette,
+			QualityScores:   photoPG.QualityScores,
+			QualityFlags:    photoPG.QualityFlags,
 		}
 
 		photosList = append(photosList, photoDB)
@@ -120,6 +215,46 @@ func (p photoPgStorage) FindAll(ctx context.Context) ([]dtos.PhotoDB, error) {
 	return photosList, nil
 }
 
+// FindIDs lists ids of not deleted photos matching filter, photos data isn't loaded
+func (p photoPgStorage) FindIDs(ctx context.Context, filter dtos.PhotoFilter) ([]string, error) {
+	query := `
+		SELECT p.id
+		FROM service.photos p
+		LEFT JOIN service.photo_metadata m ON m.photo_id = p.id
+	`
+
+	where, orderBy, args := photoFilterSQL(filter)
+	if where == "" {
+		where = " WHERE NOT p.is_deleted"
+	} else {
+		where += " AND NOT p.is_deleted"
+	}
+
+	query += where + orderBy
+
+	ids := make([]string, 0)
+
+	rows, err := p.client.Query(ctx, query, args...)
+	if err != nil {
+		return ids, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return ids, nil
+}
+
 func (p photoPgStorage) FindOne(ctx context.Context, id string) (dtos.PhotoDB, error) {
 	query := `
 		SELECT id, 
@@ -127,7 +262,15 @@ func (p photoPgStorage) FindOne(ctx context.Context, id string) (dtos.PhotoDB, e
 		       data_75,
 		       data_50,
 		       data_25,
-		       is_deleted
+		       is_deleted,
+		       removed_exif_tags,
+		       phash,
+		       duplicate_of,
+		       blurhash,
+		       palette,
+		       options,
+		       quality_scores,
+		       quality_flags
 		FROM service.photos
 		WHERE id = $1;
 	`
@@ -140,6 +283,14 @@ func (p photoPgStorage) FindOne(ctx context.Context, id string) (dtos.PhotoDB, e
 		&photoPG.Data50,
 		&photoPG.Data25,
 		&photoPG.IsDeleted,
+		&photoPG.RemovedExifTags,
+		&photoPG.PHash,
+		&photoPG.DuplicateOf,
+		&photoPG.BlurHash,
+		&photoPG.Palette,
+		&photoPG.Options,
+		&photoPG.QualityScores,
+		&photoPG.QualityFlags,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -155,12 +306,45 @@ func (p photoPgStorage) FindOne(ctx context.Context, id string) (dtos.PhotoDB, e
 		Data75:     photoPG.Data75.String,
 		Data50:     photoPG.Data50.String,
 		Data25:     photoPG.Data25.String,
-		IsDeleted:  false,
+		IsDeleted:  photoPG.IsDeleted.Bool,
+
+		RemovedExifTags: photoPG.RemovedExifTags,
+		PerceptualHash:  phashFromColumn(photoPG.PHash),
+		DuplicateOf:     photoPG.DuplicateOf.String,
+		BlurHash:        photoPG.BlurHash.String,
+		Palette:         photoPG.Palette,
+		Options:         photoPG.Options,
+		QualityScores:   photoPG.QualityScores,
+		QualityFlags:    photoPG.QualityFlags,
 	}
 
 	return photoDB, nil
 }
 
+func (p photoPgStorage) FindVersion(ctx context.Context, id string) (dtos.PhotoVersion, error) {
+	query := `
+		SELECT updated_at,
+		       is_deleted,
+		       options
+		FROM service.photos
+		WHERE id = $1;
+	`
+
+	var version dtos.PhotoVersion
+	err := p.client.QueryRow(ctx, query, id).Scan(&version.UpdatedAt, &version.IsDeleted, &version.Options)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.PhotoVersion{}, ErrNoPhotoFound
+		}
+
+		return dtos.PhotoVersion{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return version, nil
+}
+
+// Update rewrites photo columns, variants are replaced when photo has them, metadata is kept as extracted at ingest.
+// Deleted photos aren't updated, so regeneration racing with delete doesn't restore photo
 func (p photoPgStorage) Update(ctx context.Context, photo dtos.PhotoDB) error {
 	query := `
 		   UPDATE service.photos 
@@ -169,29 +353,74 @@ func (p photoPgStorage) Update(ctx context.Context, photo dtos.PhotoDB) error {
 		       data_75 = $2,
 		       data_50 = $3,
 		       data_25 = $4,
-		       is_deleted = $5
-           WHERE id = $6;
+		       removed_exif_tags = $5,
+		       phash = $6,
+		       phash_bands = $7,
+		       duplicate_of = $8,
+		       blurhash = $9,
+		       palette = $10,
+		       options = $11,
+		       quality_scores = $12,
+		       quality_flags = $13,
+		       updated_at = now()
+           WHERE id = $14 AND NOT is_deleted;
 `
 
-	_, err := p.client.Exec(ctx, query,
+	phash, phashBands := phashColumns(photo.PerceptualHash)
+
+	tx, err := p.client.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("client.Begin() failed: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			p.logger.Error().Err(err).Msg("tx.Rollback() failed")
+		}
+	}()
+
+	commandTag, err := tx.Exec(ctx, query,
 		photo.DataOrigin,
 		photo.Data75,
 		photo.Data50,
 		photo.Data25,
-		photo.IsDeleted,
+		photo.RemovedExifTags,
+		phash,
+		phashBands,
+		null.NewString(photo.DuplicateOf, photo.DuplicateOf != ""),
+		photo.BlurHash,
+		photoPalette(photo.Palette),
+		photo.Options,
+		photo.QualityScores,
+		photoQualityFlags(photo.QualityFlags),
 		photo.ID,
 	)
 	if err != nil {
-		return fmt.Errorf("client.Query() failed: %w", err)
+		return fmt.Errorf("tx.Exec() failed: %w", err)
+	}
+	if commandTag.RowsAffected() != 1 {
+		return ErrNoPhotoFound
+	}
+
+	if photo.Variants != nil {
+		if err := replaceVariants(ctx, tx, photo.ID, photo.Variants); err != nil {
+			return fmt.Errorf("replaceVariants() failed: %w", err)
+		}
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit() failed: %w", err)
+	}
+
+	p.logger.Debug().Msgf("photo with id = %s UPDATED", photo.ID)
+
 	return nil
 }
 
 func (p photoPgStorage) Delete(ctx context.Context, id string) error {
 	query := `
 		 UPDATE service.photos 
-		   SET is_deleted = TRUE
+		   SET is_deleted = TRUE,
+		       updated_at = now()
            WHERE id = $1;
 	`
 
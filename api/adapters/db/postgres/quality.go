@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// FindQualityScores returns quality of not deleted photos of ids, scores are nil for photos stored before quality checks
+func (p photoPgStorage) FindQualityScores(ctx context.Context, ids []string) ([]dtos.BurstPhoto, error) {
+	query := `
+		SELECT id,
+		       quality_scores,
+		       quality_flags
+		FROM service.photos
+		WHERE id = ANY($1::uuid[])
+		  AND is_deleted = FALSE;
+	`
+
+	photos := make([]dtos.BurstPhoto, 0, len(ids))
+
+	rows, err := p.client.Query(ctx, query, ids)
+	if err != nil {
+		return photos, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo dtos.BurstPhoto
+
+		if err = rows.Scan(&photo.ID, &photo.QualityScores, &photo.QualityFlags); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		photos = append(photos, photo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return photos, nil
+}
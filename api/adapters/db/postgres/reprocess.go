@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/clients/postgresql"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+var ErrNoReprocessJobFound = customErrors.ErrReprocessJobNotFound
+
+type reprocessJobPgStorage struct {
+	client postgresql.Client
+	logger *zerolog.Logger
+}
+
+func NewReprocessJobStoragePG(client postgresql.Client, logger *zerolog.Logger) clients.ReprocessJobStorage {
+	return &reprocessJobPgStorage{
+		client: client,
+		logger: logger,
+	}
+}
+
+func (p reprocessJobPgStorage) Create(ctx context.Context, job *dtos.ReprocessJob) error {
+	query := `
+		INSERT INTO service.reprocess_jobs
+		    (
+		     total,
+		     processed,
+		     failed
+		     )
+		VALUES
+		       ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := p.client.QueryRow(ctx, query,
+		job.Total,
+		job.Processed,
+		job.Failed,
+	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	p.logger.Info().Msgf("reprocess job created with id: %v", job.ID)
+
+	return nil
+}
+
+func (p reprocessJobPgStorage) FindOne(ctx context.Context, id string) (dtos.ReprocessJob, error) {
+	query := `
+		SELECT id,
+		       total,
+		       processed,
+		       failed,
+		       created_at,
+		       updated_at
+		FROM service.reprocess_jobs
+		WHERE id = $1;
+	`
+
+	var job dtos.ReprocessJob
+
+	if err := p.client.QueryRow(ctx, query, id).Scan(
+		&job.ID,
+		&job.Total,
+		&job.Processed,
+		&job.Failed,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.ReprocessJob{}, ErrNoReprocessJobFound
+		}
+
+		return dtos.ReprocessJob{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return job, nil
+}
+
+// AddProgress increments counters in place, so concurrent consumers never overwrite each other progress
+func (p reprocessJobPgStorage) AddProgress(ctx context.Context, id string, processed, failed int) error {
+	query := `
+		UPDATE service.reprocess_jobs
+		   SET processed = processed + $1,
+		       failed = failed + $2,
+		       updated_at = now()
+		 WHERE id = $3;
+	`
+
+	commandTag, err := p.client.Exec(ctx, query, processed, failed, id)
+	if err != nil {
+		return fmt.Errorf("client.Exec() failed: %w", err)
+	}
+	if commandTag.RowsAffected() != 1 {
+		return ErrNoReprocessJobFound
+	}
+
+	return nil
+}
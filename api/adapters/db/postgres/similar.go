@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/null/v5"
+
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Hash is split to phashBands bands of phashBandBits, photos within distance < phashBands
+// share at least one exact band, so such searches go through GIN index of phash_bands
+const (
+	phashBands    = 4
+	phashBandBits = 16
+	phashBandMask = 1<<phashBandBits - 1
+)
+
+// phashBandValues encodes band position into value, so equal chunks of different bands don't match
+func phashBandValues(hash uint64) []int32 {
+	bands := make([]int32, phashBands)
+
+	for i := range bands {
+		chunk := (hash >> (i * phashBandBits)) & phashBandMask
+		bands[i] = int32(i<<phashBandBits) | int32(chunk)
+	}
+
+	return bands
+}
+
+func phashColumns(hash *uint64) (null.Int, []int32) {
+	if hash == nil {
+		return null.Int{}, nil
+	}
+
+	return null.IntFrom(int64(*hash)), phashBandValues(*hash)
+}
+
+func phashFromColumn(value null.Int) *uint64 {
+	if !value.Valid {
+		return nil
+	}
+
+	hash := uint64(value.Int64)
+
+	return &hash
+}
+
+func (p photoPgStorage) FindSimilar(ctx context.Context, hash uint64, threshold, limit int) ([]dtos.SimilarPhoto, error) {
+	query := `
+		SELECT id,
+		       bit_count((phash # $1)::bit(64)) AS distance
+		FROM service.photos
+		WHERE is_deleted = FALSE
+		  AND phash IS NOT NULL
+		  AND bit_count((phash # $1)::bit(64)) <= $2
+	`
+
+	args := []any{int64(hash), threshold}
+
+	if threshold < phashBands {
+		args = append(args, phashBandValues(hash))
+		query += fmt.Sprintf(" AND phash_bands && $%d", len(args))
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY distance, id LIMIT $%d", len(args))
+
+	similar := make([]dtos.SimilarPhoto, 0)
+
+	rows, err := p.client.Query(ctx, query, args...)
+	if err != nil {
+		return similar, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo dtos.SimilarPhoto
+
+		if err = rows.Scan(&photo.ID, &photo.Distance); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		similar = append(similar, photo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return similar, nil
+}
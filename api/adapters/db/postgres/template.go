@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/clients/postgresql"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+var ErrNoTemplateFound = customErrors.ErrTemplateNotFound
+
+type templatePgStorage struct {
+	client postgresql.Client
+	logger *zerolog.Logger
+}
+
+func NewTemplateStoragePG(client postgresql.Client, logger *zerolog.Logger) clients.TemplateStorage {
+	return &templatePgStorage{
+		client: client,
+		logger: logger,
+	}
+}
+
+func (p templatePgStorage) Create(ctx context.Context, template *dtos.Template) error {
+	query := `
+		INSERT INTO service.templates
+		    (
+		     name,
+		     width,
+		     height,
+		     regions,
+		     caption,
+		     data
+		     )
+		VALUES
+		       ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	if err := p.client.QueryRow(ctx, query,
+		template.Name,
+		template.Width,
+		template.Height,
+		template.Regions,
+		template.Caption,
+		template.Data,
+	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	p.logger.Info().Msgf("template created with id: %v", template.ID)
+
+	return nil
+}
+
+func (p templatePgStorage) FindAll(ctx context.Context) ([]dtos.Template, error) {
+	query := `
+		SELECT id,
+		       name,
+		       width,
+		       height,
+		       regions,
+		       caption,
+		       created_at,
+		       updated_at
+		FROM service.templates
+		WHERE NOT is_deleted
+		ORDER BY created_at DESC;
+	`
+
+	templates := make([]dtos.Template, 0)
+
+	rows, err := p.client.Query(ctx, query)
+	if err != nil {
+		return templates, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var template dtos.Template
+
+		if err = rows.Scan(
+			&template.ID,
+			&template.Name,
+			&template.Width,
+			&template.Height,
+			&template.Regions,
+			&template.Caption,
+			&template.CreatedAt,
+			&template.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		templates = append(templates, template)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (p templatePgStorage) FindOne(ctx context.Context, id string) (dtos.Template, error) {
+	query := `
+		SELECT id,
+		       name,
+		       width,
+		       height,
+		       regions,
+		       caption,
+		       data,
+		       created_at,
+		       updated_at,
+		       is_deleted
+		FROM service.templates
+		WHERE id = $1;
+	`
+
+	var template dtos.Template
+
+	if err := p.client.QueryRow(ctx, query, id).Scan(
+		&template.ID,
+		&template.Name,
+		&template.Width,
+		&template.Height,
+		&template.Regions,
+		&template.Caption,
+		&template.Data,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+		&template.IsDeleted,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.Template{}, ErrNoTemplateFound
+		}
+
+		return dtos.Template{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return template, nil
+}
+
+func (p templatePgStorage) FindVersion(ctx context.Context, id string) (time.Time, error) {
+	query := `
+		SELECT updated_at
+		FROM service.templates
+		WHERE id = $1;
+	`
+
+	var updatedAt time.Time
+
+	if err := p.client.QueryRow(ctx, query, id).Scan(&updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNoTemplateFound
+		}
+
+		return time.Time{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return updatedAt, nil
+}
+
+func (p templatePgStorage) Update(ctx context.Context, template *dtos.Template) error {
+	query := `
+		UPDATE service.templates
+		   SET name = $1,
+		       width = $2,
+		       height = $3,
+		       regions = $4,
+		       caption = $5,
+		       data = $6,
+		       updated_at = now()
+		 WHERE id = $7 AND NOT is_deleted
+		RETURNING updated_at;
+	`
+
+	if err := p.client.QueryRow(ctx, query,
+		template.Name,
+		template.Width,
+		template.Height,
+		template.Regions,
+		template.Caption,
+		template.Data,
+		template.ID,
+	).Scan(&template.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoTemplateFound
+		}
+
+		return fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	p.logger.Debug().Msgf("template with id = %s UPDATED", template.ID)
+
+	return nil
+}
+
+// Delete hides template from library, FindOne still returns it for photos framed into it
+func (p templatePgStorage) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE service.templates
+		   SET is_deleted = TRUE
+		 WHERE id = $1 AND NOT is_deleted;
+	`
+
+	commandTag, err := p.client.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("client.Exec() failed: %w", err)
+	}
+	if commandTag.RowsAffected() != 1 {
+		return customErrors.ErrNoRowsFindToDelete
+	}
+
+	p.logger.Debug().Msgf("template with id = %s DELETED", id)
+
+	return nil
+}
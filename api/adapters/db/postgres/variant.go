@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+var ErrNoVariantFound = errors.New("didn't find photo variant")
+
+// replaceVariants drops all photo variants, so profiles removed from config don't leave stale ones, and inserts new
+func replaceVariants(ctx context.Context, tx pgx.Tx, photoID string, variants []dtos.PhotoVariant) error {
+	query := `
+		DELETE FROM service.photo_variants
+		WHERE photo_id = $1;
+	`
+
+	if _, err := tx.Exec(ctx, query, photoID); err != nil {
+		return fmt.Errorf("tx.Exec() failed: %w", err)
+	}
+
+	return insertVariants(ctx, tx, photoID, variants)
+}
+
+func insertVariants(ctx context.Context, tx pgx.Tx, photoID string, variants []dtos.PhotoVariant) error {
+	query := `
+		INSERT INTO service.photo_variants
+		    (
+		     photo_id,
+		     name,
+		     mime_type,
+		     width,
+		     height,
+		     quality,
+		     size_bytes,
+		     data
+		     )
+		VALUES
+		       ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for i := range variants {
+		variant := &variants[i]
+		variant.PhotoID = photoID
+
+		if _, err := tx.Exec(ctx, query,
+			variant.PhotoID,
+			variant.Name,
+			variant.MimeType,
+			variant.Width,
+			variant.Height,
+			variant.Quality,
+			variant.Size,
+			variant.Data,
+		); err != nil {
+			return fmt.Errorf("tx.Exec() failed for variant %s %s: %w", variant.Name, variant.MimeType, err)
+		}
+	}
+
+	return nil
+}
+
+// FindVariant returns variant of profile name encoded as mimeType
+func (p photoPgStorage) FindVariant(ctx context.Context, photoID, name, mimeType string) (dtos.PhotoVariant, error) {
+	query := `
+		SELECT photo_id,
+		       name,
+		       mime_type,
+		       width,
+		       height,
+		       quality,
+		       size_bytes,
+		       data
+		FROM service.photo_variants
+		WHERE photo_id = $1 AND name = $2 AND mime_type = $3;
+	`
+
+	var variant dtos.PhotoVariant
+	err := p.client.QueryRow(ctx, query, photoID, name, mimeType).Scan(
+		&variant.PhotoID,
+		&variant.Name,
+		&variant.MimeType,
+		&variant.Width,
+		&variant.Height,
+		&variant.Quality,
+		&variant.Size,
+		&variant.Data,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dtos.PhotoVariant{}, ErrNoVariantFound
+		}
+
+		return dtos.PhotoVariant{}, fmt.Errorf("client.QueryRow() failed: %w", err)
+	}
+
+	return variant, nil
+}
+
+// FindVariants lists variants of photo without data
+func (p photoPgStorage) FindVariants(ctx context.Context, photoID string) ([]dtos.PhotoVariant, error) {
+	query := `
+		SELECT photo_id,
+		       name,
+		       mime_type,
+		       width,
+		       height,
+		       quality,
+		       size_bytes
+		FROM service.photo_variants
+		WHERE photo_id = $1
+		ORDER BY name, mime_type;
+	`
+
+	variants := make([]dtos.PhotoVariant, 0)
+
+	rows, err := p.client.Query(ctx, query, photoID)
+	if err != nil {
+		return variants, fmt.Errorf("client.Query() failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var variant dtos.PhotoVariant
+
+		if err = rows.Scan(
+			&variant.PhotoID,
+			&variant.Name,
+			&variant.MimeType,
+			&variant.Width,
+			&variant.Height,
+			&variant.Quality,
+			&variant.Size,
+		); err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %w", err)
+		}
+
+		variants = append(variants, variant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("client.Query() failed: %w", err)
+	}
+
+	return variants, nil
+}
@@ -2,6 +2,7 @@ package rmq
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -27,7 +28,12 @@ func NewPhotoProducer(client *amqp.Connection, photoQueue rabbitmq.PhotoQueue, l
 	}
 }
 
-func (p photoProducer) Publish(photo *dtos.Photo) error {
+func (p photoProducer) Publish(message *dtos.PhotoMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("json.Marshal() failed: %w", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Create a context with a 3-second timeout
 	defer cancel()
 
@@ -44,8 +50,8 @@ func (p photoProducer) Publish(photo *dtos.Photo) error {
 		false,             // mandatory (if true, the server will return an unroutable message)
 		false,             // immediate (if true, the server will return an undeliverable message)
 		amqp.Publishing{
-			ContentType: "text/plain",       // Content type of the message
-			Body:        []byte(photo.Data), // Message body as a byte array
+			ContentType: "application/json", // Content type of the message
+			Body:        body,               // Message body as a byte array
 		},
 	); err != nil {
 		return fmt.Errorf("failed to publish a message: %v", err)
@@ -0,0 +1,180 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+// Viper config keys
+const (
+	configImportTimeout         = "import.timeoutSec"
+	configImportMaxSize         = "import.maxSizeBytes"
+	configImportMaxRedirects    = "import.maxRedirects"
+	configImportAllowedNetworks = "import.allowedNetworks"
+)
+
+const (
+	defaultTimeout      = 15
+	defaultMaxSize      = 20 << 20
+	defaultMaxRedirects = 3
+)
+
+// carrierGradeNAT isn't covered by net.IP.IsPrivate, but is not public either
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type photoHTTPFetcher struct {
+	client  *http.Client
+	maxSize int64
+	logger  *zerolog.Logger
+}
+
+// NewPhotoFetcherHTTP creates fetcher for importing photos by url.
+// Connections to private, loopback and link-local addresses are denied unless listed in import.allowedNetworks
+func NewPhotoFetcherHTTP(logger *zerolog.Logger) (clients.PhotoFetcher, error) {
+	viper.SetDefault(configImportTimeout, defaultTimeout)
+	viper.SetDefault(configImportMaxSize, defaultMaxSize)
+	viper.SetDefault(configImportMaxRedirects, defaultMaxRedirects)
+
+	allowedNetworks := make([]*net.IPNet, 0)
+	for _, cidr := range viper.GetStringSlice(configImportAllowedNetworks) {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("net.ParseCIDR() failed: %w", err)
+		}
+
+		allowedNetworks = append(allowedNetworks, ipNet)
+	}
+
+	timeout := time.Duration(viper.GetInt(configImportTimeout)) * time.Second
+	maxRedirects := viper.GetInt(configImportMaxRedirects)
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// Checked on connect, so resolved address of every redirect is validated as well
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkAddressAllowed(address, allowedNetworks)
+		},
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // proxy would bypass dialer address checks
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return customErrors.ErrTooManyRedirects
+			}
+
+			return checkURLScheme(req.URL)
+		},
+	}
+
+	return &photoHTTPFetcher{
+		client:  client,
+		maxSize: viper.GetInt64(configImportMaxSize),
+		logger:  logger,
+	}, nil
+}
+
+func (f photoHTTPFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	photoURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse() failed: %w", err)
+	}
+
+	if err := checkURLScheme(photoURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, photoURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext() failed: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client.Do() failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			f.logger.Error().Err(customErrors.ErrorBodyCloseFailed).Err(err).Send()
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", customErrors.ErrRemoteFetchFailed, resp.StatusCode)
+	}
+
+	if resp.ContentLength > f.maxSize {
+		return nil, customErrors.ErrPhotoTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll() failed: %w", err)
+	}
+
+	if int64(len(data)) > f.maxSize {
+		return nil, customErrors.ErrPhotoTooLarge
+	}
+
+	// Content-Type header is not trusted, type is sniffed from content
+	mimeType := utils.GetB64MimeType(data)
+	if !utils.IsSupportedPhotoMimeType(mimeType) {
+		return nil, fmt.Errorf("%w: %s", customErrors.ErrUnsupportedPhotoType, mimeType)
+	}
+
+	f.logger.Debug().Msgf("photo fetched from %s, size=%d, type=%s", photoURL.Host, len(data), mimeType)
+
+	return data, nil
+}
+
+func checkURLScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", customErrors.ErrRemoteURLNotAllowed, u.Scheme)
+	}
+
+	return nil
+}
+
+func checkAddressAllowed(address string, allowedNetworks []*net.IPNet) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("net.SplitHostPort() failed: %w", err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", customErrors.ErrRemoteURLNotAllowed, host)
+	}
+
+	for _, ipNet := range allowedNetworks {
+		if ipNet.Contains(ip) {
+			return nil
+		}
+	}
+
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || carrierGradeNAT.Contains(ip) {
+		return fmt.Errorf("%w: %s", customErrors.ErrRemoteURLNotAllowed, ip)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+const (
+	// derivedShardLength is length of key prefix used as subdirectory, so no directory grows too large
+	derivedShardLength = 2
+	// derivedSweepRatio is share of maxBytes cache is trimmed to, so full cache isn't swept on every Set
+	derivedSweepRatio = 0.9
+
+	tmpFileExt = ".tmp"
+)
+
+// derivedDiskCache evicts least recently used images once total size exceeds maxBytes,
+// Get touches file mtime, so mtime tells when image was used last
+type derivedDiskCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	size     int64
+	logger   *zerolog.Logger
+}
+
+type derivedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func NewDerivedCacheDisk(dir string, maxBytes int64, logger *zerolog.Logger) (clients.DerivedCache, error) {
+	if err := os.MkdirAll(dir, dirPermission); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+
+	cache := &derivedDiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		logger:   logger,
+	}
+
+	// Images cached before restart count against budget too
+	files, err := cache.files()
+	if err != nil {
+		return nil, fmt.Errorf("cache.files() failed: %w", err)
+	}
+
+	for _, file := range files {
+		cache.size += file.size
+	}
+
+	return cache, nil
+}
+
+func (s *derivedDiskCache) Get(_ context.Context, key string) ([]byte, error) {
+	path := s.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, customErrors.ErrCacheMiss
+		}
+
+		return nil, fmt.Errorf("os.ReadFile() failed: %w", err)
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Warn().Err(err).Msg("os.Chtimes() failed")
+	}
+
+	return data, nil
+}
+
+// Set writes data to temp file first, so concurrent Get never reads partially written image
+func (s *derivedDiskCache) Set(_ context.Context, key string, data []byte) error {
+	// image which doesn't fit would evict whole cache and be dropped itself
+	if int64(len(data)) > s.maxBytes {
+		return nil
+	}
+
+	path := s.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(path), dirPermission); err != nil {
+		return fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-*"+tmpFileExt)
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp() failed: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tmpFile.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error().Err(err).Msg("os.Remove() failed")
+		}
+	}()
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		s.logger.Error().Err(customErrors.ErrorOsCloseFailed).Err(closeErr).Send()
+	}
+	if err != nil {
+		return fmt.Errorf("tmpFile.Write() failed: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("os.Rename() failed: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.size += int64(len(data))
+	if s.size <= s.maxBytes {
+		return nil
+	}
+
+	if err := s.evict(); err != nil {
+		return fmt.Errorf("s.evict() failed: %w", err)
+	}
+
+	return nil
+}
+
+// evict removes least recently used images until cache fits into derivedSweepRatio of maxBytes.
+// Size is recounted from disk, so rewritten keys and concurrent writers don't skew it
+func (s *derivedDiskCache) evict() error {
+	files, err := s.files()
+	if err != nil {
+		return fmt.Errorf("s.files() failed: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	var size int64
+	for _, file := range files {
+		size += file.size
+	}
+
+	target := int64(float64(s.maxBytes) * derivedSweepRatio)
+
+	for _, file := range files {
+		if size <= target {
+			break
+		}
+
+		if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("os.Remove() failed: %w", err)
+		}
+
+		size -= file.size
+	}
+
+	s.size = size
+
+	return nil
+}
+
+// files lists cached images, temp files being written are skipped
+func (s *derivedDiskCache) files() ([]derivedFile, error) {
+	files := make([]derivedFile, 0)
+
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// File removed while walking is already evicted
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), tmpFileExt) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		files = append(files, derivedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filepath.WalkDir() failed: %w", err)
+	}
+
+	return files, nil
+}
+
+func (s *derivedDiskCache) path(key string) string {
+	key = filepath.Base(key)
+	if len(key) <= derivedShardLength {
+		return filepath.Join(s.dir, key)
+	}
+
+	return filepath.Join(s.dir, key[:derivedShardLength], key)
+}
@@ -0,0 +1,181 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+const (
+	dirPermission  = 0o750
+	filePermission = 0o600
+
+	uploadFileExt    = ".upload"
+	finalizedFileExt = ".finalized"
+)
+
+type uploadDiskStorage struct {
+	dir    string
+	logger *zerolog.Logger
+}
+
+func NewUploadStorageDisk(dir string, logger *zerolog.Logger) (clients.UploadStorage, error) {
+	if err := os.MkdirAll(dir, dirPermission); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll() failed: %w", err)
+	}
+
+	return &uploadDiskStorage{
+		dir:    dir,
+		logger: logger,
+	}, nil
+}
+
+// Save writes data to temp file first, so partially uploaded photo is never finalized
+func (s uploadDiskStorage) Save(_ context.Context, id string, data io.Reader, maxSize int64) error {
+	if utils.IsFileExists(s.finalizedPath(id)) {
+		return customErrors.ErrUploadFinalized
+	}
+
+	tmpFile, err := os.CreateTemp(s.dir, id+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp() failed: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tmpFile.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error().Err(err).Msg("os.Remove() failed")
+		}
+	}()
+
+	written, err := io.Copy(tmpFile, io.LimitReader(data, maxSize+1))
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		s.logger.Error().Err(customErrors.ErrorOsCloseFailed).Err(closeErr).Send()
+	}
+	if err != nil {
+		return fmt.Errorf("io.Copy() failed: %w", err)
+	}
+
+	if written > maxSize {
+		return customErrors.ErrPhotoTooLarge
+	}
+
+	if err := os.Chmod(tmpFile.Name(), filePermission); err != nil {
+		return fmt.Errorf("os.Chmod() failed: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.path(id)); err != nil {
+		return fmt.Errorf("os.Rename() failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s uploadDiskStorage) Load(_ context.Context, id string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, customErrors.ErrUploadNotFound
+		}
+
+		return nil, fmt.Errorf("os.ReadFile() failed: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s uploadDiskStorage) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, customErrors.ErrUploadNotFound
+		}
+
+		return nil, fmt.Errorf("os.Open() failed: %w", err)
+	}
+
+	return file, nil
+}
+
+func (s uploadDiskStorage) Delete(_ context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("os.Remove() failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFinalized creates marker file exclusively, so concurrent finalizations of one upload don't both succeed
+func (s uploadDiskStorage) MarkFinalized(_ context.Context, id string) error {
+	marker, err := os.OpenFile(s.finalizedPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePermission)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return customErrors.ErrUploadFinalized
+		}
+
+		return fmt.Errorf("os.OpenFile() failed: %w", err)
+	}
+
+	if err := marker.Close(); err != nil {
+		s.logger.Error().Err(customErrors.ErrorOsCloseFailed).Err(err).Send()
+	}
+
+	return nil
+}
+
+func (s uploadDiskStorage) UnmarkFinalized(_ context.Context, id string) error {
+	if err := os.Remove(s.finalizedPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("os.Remove() failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOlderThan removes uploads which were never finalized and markers of consumed ones, their urls are expired.
+// Finalized uploads wait for consumer, which deletes them
+func (s uploadDiskStorage) DeleteOlderThan(_ context.Context, before time.Time) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("os.ReadDir() failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+
+		name := entry.Name()
+		id := strings.TrimSuffix(strings.TrimSuffix(name, uploadFileExt), finalizedFileExt)
+
+		switch {
+		case strings.HasSuffix(name, uploadFileExt) && utils.IsFileExists(s.finalizedPath(id)):
+			continue
+		case strings.HasSuffix(name, finalizedFileExt) && utils.IsFileExists(s.path(id)):
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("os.Remove() failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s uploadDiskStorage) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+uploadFileExt)
+}
+
+func (s uploadDiskStorage) finalizedPath(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+finalizedFileExt)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type BackgroundUseCase interface {
+	Create(name, data string) (dtos.Background, error)
+	GetAll() ([]dtos.Background, error)
+	GetByID(id string) (dtos.Background, error)
+	Delete(id string) error
+}
+
+type BackgroundHandler struct {
+	backgroundUseCase BackgroundUseCase
+	log               *zerolog.Logger
+}
+
+func NewBackgroundHandler(backgroundUseCase BackgroundUseCase, log *zerolog.Logger) BackgroundHandler {
+	return BackgroundHandler{
+		backgroundUseCase: backgroundUseCase,
+		log:               log,
+	}
+}
+
+type CreateBackgroundRequest struct {
+	Name string `json:"name" validate:"required"`
+	Data string `json:"data" validate:"required"` // JPEG or PNG, b64 or data URI
+}
+
+func (h BackgroundHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestData := new(CreateBackgroundRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	background, err := h.backgroundUseCase.Create(requestData.Name, requestData.Data)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrUnsupportedPhotoType) || errors.Is(err, customErrors.ErrInvalidDataURI) ||
+			errors.Is(err, customErrors.ErrMimeTypeMismatch) {
+			statusCode = http.StatusBadRequest
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("backgroundUseCase.Create(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, background)
+}
+
+func (h BackgroundHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	backgrounds, err := h.backgroundUseCase.GetAll()
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("backgroundUseCase.GetAll(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	Respond(w, h.log, backgrounds)
+}
+
+func (h BackgroundHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	background, err := h.backgroundUseCase.GetByID(id)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("backgroundUseCase.GetByID(): %w", err), backgroundErrStatusCode(err))
+
+		return
+	}
+
+	Respond(w, h.log, background)
+}
+
+func (h BackgroundHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.backgroundUseCase.Delete(id); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("backgroundUseCase.Delete(): %w", err), backgroundErrStatusCode(err))
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
+
+func backgroundErrStatusCode(err error) int {
+	if errors.Is(err, customErrors.ErrBackgroundNotFound) || errors.Is(err, customErrors.ErrNoRowsFindToDelete) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type BoomerangPublishUseCase interface {
+	AddBoomerangInQueue(boomerang *dtos.Boomerang) error
+}
+
+type BoomerangHandler struct {
+	boomerangPublishUseCase BoomerangPublishUseCase
+	log                     *zerolog.Logger
+}
+
+func NewBoomerangHandler(boomerangPublishUseCase BoomerangPublishUseCase, log *zerolog.Logger) BoomerangHandler {
+	return BoomerangHandler{
+		boomerangPublishUseCase: boomerangPublishUseCase,
+		log:                     log,
+	}
+}
+
+type CreateBoomerangRequest struct {
+	PhotoIDs []string               `json:"photoIds" validate:"required_without=Data,dive,uuid"`
+	Data     string                 `json:"data" validate:"required_without=PhotoIDs"` // animated GIF, b64 or data URI
+	DelayMs  int                    `json:"delayMs"`
+	Width    int                    `json:"width"`
+	Options  dtos.ProcessingOptions `json:"options"`
+}
+
+func (h BoomerangHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestData := new(CreateBoomerangRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	boomerang := &dtos.Boomerang{
+		PhotoIDs: requestData.PhotoIDs,
+		Data:     requestData.Data,
+		DelayMs:  requestData.DelayMs,
+		Width:    requestData.Width,
+		Options:  requestData.Options,
+	}
+
+	if err := h.boomerangPublishUseCase.AddBoomerangInQueue(boomerang); err != nil {
+		statusCode := publishErrStatusCode(err)
+		if errors.Is(err, customErrors.ErrInvalidBoomerang) {
+			statusCode = http.StatusBadRequest
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("boomerangPublishUseCase.AddBoomerangInQueue(): %w", err), statusCode)
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/rs/zerolog"
 
 	"test-task-photo-booth/src/entities"
 )
 
+// imageCacheControl is short, since images are regenerated on reprocess and photos may be deleted
+const imageCacheControl = "public, max-age=3600"
+
 func Respond(w http.ResponseWriter, log *zerolog.Logger, data any) {
 	if data == nil {
 		return
@@ -72,6 +76,18 @@ func RespondNativeJSON(w http.ResponseWriter, log *zerolog.Logger, data []byte)
 	}
 }
 
+// RespondImage writes image bytes, they may be cached by clients for a while
+func RespondImage(w http.ResponseWriter, log *zerolog.Logger, mimeType string, data []byte) {
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", imageCacheControl)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		log.Error().Err(fmt.Errorf("writing response failed: %w", err)).Msg("responding with image")
+	}
+}
+
 func RespondErr(w http.ResponseWriter, log *zerolog.Logger, err error, statusCode int) {
 	log.Error().Err(err).Msg("responding with error")
 
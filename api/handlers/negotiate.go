@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptedMimeTypes returns offered mime types client accepts, most preferred first. Types listed explicitly win
+// over equally weighted wildcards, remaining ties keep offered order, so offered list starts with the fallback.
+// Request without Accept header accepts anything
+func acceptedMimeTypes(r *http.Request, offered []string) []string {
+	weights := make(map[string]float64)
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		accept = "*/*"
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		weight := 1.0
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				weight = parsed
+			}
+		}
+
+		if current, ok := weights[mediaType]; !ok || weight > current {
+			weights[mediaType] = weight
+		}
+	}
+
+	type candidate struct {
+		mimeType string
+		weight   float64
+		explicit bool
+	}
+
+	candidates := make([]candidate, 0, len(offered))
+
+	for _, mimeType := range offered {
+		kind, _, _ := strings.Cut(mimeType, "/")
+
+		c := candidate{mimeType: mimeType}
+		if weight, ok := weights[mimeType]; ok {
+			c.weight, c.explicit = weight, true
+		} else if weight, ok := weights[kind+"/*"]; ok {
+			c.weight = weight
+		} else {
+			c.weight = weights["*/*"]
+		}
+
+		if c.weight > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].weight != candidates[j].weight {
+			return candidates[i].weight > candidates[j].weight
+		}
+
+		return candidates[i].explicit && !candidates[j].explicit
+	})
+
+	accepted := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		accepted = append(accepted, c.mimeType)
+	}
+
+	return accepted
+}
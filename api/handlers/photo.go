@@ -1,24 +1,43 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
 	"test-task-photo-booth/src/entities/dtos"
 )
 
 type PhotoUseCase interface {
-	GetAllPhotos() ([]dtos.Photo, error)
+	GetAllPhotos(filter dtos.PhotoFilter) ([]dtos.Photo, error)
 	GetByID(id, quality string) (dtos.Photo, error)
+	GetMetadata(id string) (dtos.PhotoMetadata, error)
+	GetAttribution(id, variant string) (dtos.Attribution, error)
+	GetVariant(id, name, format string) (dtos.PhotoVariant, error)
+	GetVariantImage(id, name string, accepted []string) (dtos.DerivedImage, error)
+	GetVariants(id string) ([]dtos.PhotoVariant, error)
+	GetSimilar(id string, threshold *int) ([]dtos.SimilarPhoto, error)
+	GetBestOfBurst(ids []string) (dtos.BestOfBurst, error)
 	Delete(id string) error
 }
 
 type PhotoPublishUseCase interface {
 	AddInQueue(photo *dtos.Photo) error
+	AddImportInQueue(photoURL string, options dtos.ProcessingOptions) error
 }
 
 type PhotoHandler struct {
@@ -36,7 +55,8 @@ func NewPhotoHandler(photoUseCase PhotoUseCase, photoPublishUseCase PhotoPublish
 }
 
 type CreatePhotoRequest struct {
-	Data string `json:"data" validate:"required"`
+	Data    string                 `json:"data" validate:"required"` // b64 or data URI (data:image/jpeg;base64,...)
+	Options dtos.ProcessingOptions `json:"options"`
 }
 
 func (h PhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -54,12 +74,61 @@ func (h PhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateProcessingOptions(requestData.Options); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validateProcessingOptions() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	photoData, _, err := utils.DecodeB64DataURI(requestData.Data)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("utils.DecodeB64DataURI() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
 	photo := &dtos.Photo{
-		Data: requestData.Data,
+		Data:    base64.StdEncoding.EncodeToString(photoData),
+		Options: requestData.Options,
 	}
 
 	if err := h.photoPublishUseCase.AddInQueue(photo); err != nil {
-		RespondErr(w, h.log, fmt.Errorf("photoUseCase.AddInQueue(): %w", err), http.StatusInternalServerError)
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.AddInQueue(): %w", err), publishErrStatusCode(err))
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
+
+type ImportPhotoRequest struct {
+	URL     string                 `json:"url" validate:"required,http_url"`
+	Options dtos.ProcessingOptions `json:"options"`
+}
+
+func (h PhotoHandler) Import(w http.ResponseWriter, r *http.Request) {
+	requestData := new(ImportPhotoRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := validateProcessingOptions(requestData.Options); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validateProcessingOptions() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.photoPublishUseCase.AddImportInQueue(requestData.URL, requestData.Options); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.AddImportInQueue(): %w", err), publishErrStatusCode(err))
 
 		return
 	}
@@ -68,7 +137,14 @@ func (h PhotoHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h PhotoHandler) GetAllPhotos(w http.ResponseWriter, r *http.Request) {
-	photos, err := h.photoUseCase.GetAllPhotos()
+	filter, err := parsePhotoFilter(r.URL.Query())
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("parsePhotoFilter() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	photos, err := h.photoUseCase.GetAllPhotos(filter)
 	if err != nil {
 		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetAllPhotos(): %w", err), http.StatusInternalServerError)
 
@@ -86,6 +162,25 @@ func (h PhotoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// variant profile takes precedence over percentage quality
+	if variantName := r.URL.Query().Get("variant"); variantName != "" {
+		variant, err := h.photoUseCase.GetVariant(id, variantName, r.URL.Query().Get("format"))
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			if errors.Is(err, customErrors.ErrUnsupportedVariantFormat) {
+				statusCode = http.StatusBadRequest
+			}
+
+			RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetVariant(): %w", err), statusCode)
+
+			return
+		}
+
+		Respond(w, h.log, variant)
+
+		return
+	}
+
 	quality := r.URL.Query().Get("quality")
 	if err := validateQuality(quality); err != nil {
 		RespondErr(w, h.log, fmt.Errorf("validateQuality() failed: %w", err), http.StatusBadRequest)
@@ -103,6 +198,310 @@ func (h PhotoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	Respond(w, h.log, photo)
 }
 
+func (h PhotoHandler) GetMetadata(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	metadata, err := h.photoUseCase.GetMetadata(id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrPhotoNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetMetadata(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, metadata)
+}
+
+// GetAttribution reads attribution back from variant, so it can be checked what shared photos carry
+func (h PhotoHandler) GetAttribution(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	attribution, err := h.photoUseCase.GetAttribution(id, r.URL.Query().Get("variant"))
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetAttribution(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	Respond(w, h.log, attribution)
+}
+
+func (h PhotoHandler) GetVariants(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	variants, err := h.photoUseCase.GetVariants(id)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetVariants(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	Respond(w, h.log, variants)
+}
+
+// GetVariantImage serves variant bytes in format negotiated by Accept header, JPEG is served by default
+func (h PhotoHandler) GetVariantImage(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	name := chi.URLParam(r, "name")
+	if id == "" || name == "" {
+		RespondErr(w, h.log, fmt.Errorf("id and name are required"), http.StatusBadRequest)
+
+		return
+	}
+
+	accepted := acceptedMimeTypes(r, []string{utils.MimeTypeJPEG, utils.MimeTypeWebP})
+
+	image, err := h.photoUseCase.GetVariantImage(id, name, accepted)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetVariantImage(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Vary", "Accept")
+	RespondImage(w, h.log, image.MimeType, image.Data)
+}
+
+// maxSimilarThreshold is max Hamming distance of 64 bit perceptual hashes
+const maxSimilarThreshold = 64
+
+func (h PhotoHandler) GetSimilar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	var threshold *int
+
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > maxSimilarThreshold {
+			RespondErr(w, h.log, fmt.Errorf("invalid threshold: %s", value), http.StatusBadRequest)
+
+			return
+		}
+
+		threshold = &parsed
+	}
+
+	similar, err := h.photoUseCase.GetSimilar(id, threshold)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrNoPerceptualHash) {
+			statusCode = http.StatusConflict
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetSimilar(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, similar)
+}
+
+type BestOfBurstRequest struct {
+	PhotoIDs []string `json:"photoIds" validate:"required,dive,uuid"`
+}
+
+// GetBestOfBurst picks sharpest photo of burst
+func (h PhotoHandler) GetBestOfBurst(w http.ResponseWriter, r *http.Request) {
+	requestData := new(BestOfBurstRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	best, err := h.photoUseCase.GetBestOfBurst(requestData.PhotoIDs)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrInvalidBurst) {
+			statusCode = http.StatusBadRequest
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("photoUseCase.GetBestOfBurst(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, best)
+}
+
+// parsePhotoFilter parses listing query:
+// ?cameraMake=&cameraModel=&capturedFrom=&capturedTo=&hasGps=&hideFlagged=&sort=-capturedAt
+func parsePhotoFilter(query url.Values) (dtos.PhotoFilter, error) {
+	filter := dtos.PhotoFilter{
+		CameraMake:  query.Get("cameraMake"),
+		CameraModel: query.Get("cameraModel"),
+	}
+
+	for key, target := range map[string]**time.Time{
+		"capturedFrom": &filter.CapturedFrom,
+		"capturedTo":   &filter.CapturedTo,
+	} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s: %w", key, err)
+		}
+
+		*target = &parsed
+	}
+
+	if value := query.Get("hasGps"); value != "" {
+		hasGPS, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid hasGps: %w", err)
+		}
+
+		filter.HasGPS = &hasGPS
+	}
+
+	if value := query.Get("hideFlagged"); value != "" {
+		hideFlagged, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid hideFlagged: %w", err)
+		}
+
+		filter.HideFlagged = hideFlagged
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		filter.SortDesc = strings.HasPrefix(sortBy, "-")
+		filter.SortBy = strings.TrimPrefix(sortBy, "-")
+
+		switch filter.SortBy {
+		case entities.PhotoSortCapturedAt, entities.PhotoSortCameraMake, entities.PhotoSortCameraModel,
+			entities.PhotoSortISO, entities.PhotoSortFocalLength:
+		default:
+			return filter, fmt.Errorf("invalid sort: %s", sortBy)
+		}
+	}
+
+	return filter, nil
+}
+
+// validateProcessingOptions checks upload options, so broken ones are rejected before queueing
+func validateProcessingOptions(options dtos.ProcessingOptions) error {
+	if _, err := filters.ParseAll(options.Filters); err != nil {
+		return fmt.Errorf("filters.ParseAll() failed: %w", err)
+	}
+
+	if options.Template != "" {
+		if err := validator.New().Var(options.Template, "uuid"); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+
+	if err := validateCaption(options.Caption); err != nil {
+		return fmt.Errorf("validateCaption() failed: %w", err)
+	}
+
+	if options.ChromaKey == nil {
+		return nil
+	}
+
+	if err := validator.New().Var(options.ChromaKey.Background, "required,uuid"); err != nil {
+		return fmt.Errorf("invalid chromaKey background: %w", err)
+	}
+
+	if options.ChromaKey.KeyColor != "" {
+		if _, err := utils.ParseHexColor(options.ChromaKey.KeyColor); err != nil {
+			return fmt.Errorf("invalid chromaKey keyColor: %w", err)
+		}
+	}
+
+	for name, value := range map[string]*float64{
+		"tolerance": options.ChromaKey.Tolerance,
+		"softness":  options.ChromaKey.Softness,
+	} {
+		if value != nil && (*value < 0 || *value > 1) {
+			return fmt.Errorf("invalid chromaKey %s: must be from 0 to 1", name)
+		}
+	}
+
+	return nil
+}
+
+// publishErrStatusCode maps errors of adding photo job in queue, library items chosen in options may be missing
+func publishErrStatusCode(err error) int {
+	switch {
+	case errors.Is(err, customErrors.ErrBackgroundNotFound), errors.Is(err, customErrors.ErrTemplateNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// maxCaptionLength is max caption text length in runes
+const maxCaptionLength = 200
+
+// validateCaption checks caption fields which are set, fonts are checked by consumer against its config
+func validateCaption(caption *dtos.CaptionOptions) error {
+	if caption == nil {
+		return nil
+	}
+
+	if caption.Text == "" || utf8.RuneCountInString(caption.Text) > maxCaptionLength {
+		return fmt.Errorf("caption text must be from 1 to %d characters", maxCaptionLength)
+	}
+
+	if caption.Position != "" && !utils.IsCaptionPositionSupported(caption.Position) {
+		return fmt.Errorf("unsupported caption position: %s", caption.Position)
+	}
+
+	for name, value := range map[string]string{
+		"color":        caption.Color,
+		"outlineColor": caption.OutlineColor,
+	} {
+		if value == "" {
+			continue
+		}
+
+		if _, err := utils.ParseHexColor(value); err != nil {
+			return fmt.Errorf("invalid caption %s: %w", name, err)
+		}
+	}
+
+	if caption.Size < 0 || caption.Size > 1 || caption.Outline < 0 || caption.Outline > 1 {
+		return fmt.Errorf("caption size and outline must be from 0 to 1")
+	}
+
+	return nil
+}
+
 func validateQuality(quality string) error {
 	isValid := true
 
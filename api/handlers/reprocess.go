@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type ReprocessUseCase interface {
+	Start(reprocess dtos.Reprocess) (dtos.ReprocessJob, error)
+	GetJob(id string) (dtos.ReprocessJob, error)
+}
+
+type ReprocessHandler struct {
+	reprocessUseCase ReprocessUseCase
+	log              *zerolog.Logger
+}
+
+func NewReprocessHandler(reprocessUseCase ReprocessUseCase, log *zerolog.Logger) ReprocessHandler {
+	return ReprocessHandler{
+		reprocessUseCase: reprocessUseCase,
+		log:              log,
+	}
+}
+
+// ReprocessRequest selects photos by exactly one of photoIds, filter or all
+type ReprocessRequest struct {
+	PhotoIDs []string                `json:"photoIds" validate:"omitempty,dive,uuid"`
+	Filter   *ReprocessFilterRequest `json:"filter"`
+	All      bool                    `json:"all"`
+}
+
+// ReprocessFilterRequest is metadata filter of photos listing, times are RFC3339
+type ReprocessFilterRequest struct {
+	CameraMake   string     `json:"cameraMake"`
+	CameraModel  string     `json:"cameraModel"`
+	CapturedFrom *time.Time `json:"capturedFrom"`
+	CapturedTo   *time.Time `json:"capturedTo"`
+	HasGPS       *bool      `json:"hasGps"`
+}
+
+func (h ReprocessHandler) Start(w http.ResponseWriter, r *http.Request) {
+	requestData := new(ReprocessRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	reprocess := dtos.Reprocess{
+		PhotoIDs: requestData.PhotoIDs,
+		All:      requestData.All,
+	}
+
+	if requestData.Filter != nil {
+		reprocess.Filter = &dtos.PhotoFilter{
+			CameraMake:   requestData.Filter.CameraMake,
+			CameraModel:  requestData.Filter.CameraModel,
+			CapturedFrom: requestData.Filter.CapturedFrom,
+			CapturedTo:   requestData.Filter.CapturedTo,
+			HasGPS:       requestData.Filter.HasGPS,
+		}
+	}
+
+	job, err := h.reprocessUseCase.Start(reprocess)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrInvalidReprocess) {
+			statusCode = http.StatusBadRequest
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("reprocessUseCase.Start(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, job)
+}
+
+func (h ReprocessHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, err := h.reprocessUseCase.GetJob(id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, customErrors.ErrReprocessJobNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("reprocessUseCase.GetJob(): %w", err), statusCode)
+
+		return
+	}
+
+	Respond(w, h.log, job)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type StripPublishUseCase interface {
+	AddStripInQueue(strip *dtos.Strip) error
+}
+
+type StripHandler struct {
+	stripPublishUseCase StripPublishUseCase
+	log                 *zerolog.Logger
+}
+
+func NewStripHandler(stripPublishUseCase StripPublishUseCase, log *zerolog.Logger) StripHandler {
+	return StripHandler{
+		stripPublishUseCase: stripPublishUseCase,
+		log:                 log,
+	}
+}
+
+type CreateStripRequest struct {
+	PhotoIDs   []string               `json:"photoIds" validate:"required,dive,uuid"`
+	Layout     string                 `json:"layout" validate:"required"`
+	Spacing    int                    `json:"spacing"`
+	Background string                 `json:"background"`
+	Frame      string                 `json:"frame"` // b64 or data URI of PNG
+	Header     int                    `json:"header"`
+	Footer     int                    `json:"footer"`
+	CellWidth  int                    `json:"cellWidth"`
+	CellHeight int                    `json:"cellHeight"`
+	Options    dtos.ProcessingOptions `json:"options"`
+}
+
+func (h StripHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestData := new(CreateStripRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	strip := &dtos.Strip{
+		PhotoIDs:   requestData.PhotoIDs,
+		Layout:     requestData.Layout,
+		Spacing:    requestData.Spacing,
+		Background: requestData.Background,
+		Frame:      requestData.Frame,
+		Header:     requestData.Header,
+		Footer:     requestData.Footer,
+		CellWidth:  requestData.CellWidth,
+		CellHeight: requestData.CellHeight,
+		Options:    requestData.Options,
+	}
+
+	if err := h.stripPublishUseCase.AddStripInQueue(strip); err != nil {
+		statusCode := publishErrStatusCode(err)
+		if errors.Is(err, customErrors.ErrInvalidStrip) {
+			statusCode = http.StatusBadRequest
+		}
+
+		RespondErr(w, h.log, fmt.Errorf("stripPublishUseCase.AddStripInQueue(): %w", err), statusCode)
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
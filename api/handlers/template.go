@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type TemplateUseCase interface {
+	Create(name, data string, regions []dtos.TemplateRegion, caption *dtos.CaptionOptions) (dtos.Template, error)
+	GetAll() ([]dtos.Template, error)
+	GetByID(id string) (dtos.Template, error)
+	Update(id, name, data string, regions []dtos.TemplateRegion, caption *dtos.CaptionOptions) (dtos.Template, error)
+	Delete(id string) error
+}
+
+type TemplateHandler struct {
+	templateUseCase TemplateUseCase
+	log             *zerolog.Logger
+}
+
+func NewTemplateHandler(templateUseCase TemplateUseCase, log *zerolog.Logger) TemplateHandler {
+	return TemplateHandler{
+		templateUseCase: templateUseCase,
+		log:             log,
+	}
+}
+
+type CreateTemplateRequest struct {
+	Name    string                `json:"name" validate:"required"`
+	Data    string                `json:"data" validate:"required"` // PNG, b64 or data URI
+	Regions []dtos.TemplateRegion `json:"regions" validate:"required"`
+	Caption *dtos.CaptionOptions  `json:"caption"`
+}
+
+func (h TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestData := new(CreateTemplateRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validate.Struct() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := validateCaption(requestData.Caption); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validateCaption() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	template, err := h.templateUseCase.Create(requestData.Name, requestData.Data, requestData.Regions, requestData.Caption)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("templateUseCase.Create(): %w", err), templateErrStatusCode(err))
+
+		return
+	}
+
+	Respond(w, h.log, template)
+}
+
+func (h TemplateHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateUseCase.GetAll()
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("templateUseCase.GetAll(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	Respond(w, h.log, templates)
+}
+
+func (h TemplateHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	template, err := h.templateUseCase.GetByID(id)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("templateUseCase.GetByID(): %w", err), templateErrStatusCode(err))
+
+		return
+	}
+
+	Respond(w, h.log, template)
+}
+
+// UpdateTemplateRequest replaces fields which are set
+type UpdateTemplateRequest struct {
+	Name    string                `json:"name"`
+	Data    string                `json:"data"` // PNG, b64 or data URI
+	Regions []dtos.TemplateRegion `json:"regions"`
+	Caption *dtos.CaptionOptions  `json:"caption"`
+}
+
+func (h TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	requestData := new(UpdateTemplateRequest)
+	if err := DecodeBody(r.Body, requestData); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("DecodeBody() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := validateCaption(requestData.Caption); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("validateCaption() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	template, err := h.templateUseCase.Update(id, requestData.Name, requestData.Data, requestData.Regions, requestData.Caption)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("templateUseCase.Update(): %w", err), templateErrStatusCode(err))
+
+		return
+	}
+
+	Respond(w, h.log, template)
+}
+
+func (h TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.templateUseCase.Delete(id); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("templateUseCase.Delete(): %w", err), templateErrStatusCode(err))
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
+
+func templateErrStatusCode(err error) int {
+	switch {
+	case errors.Is(err, customErrors.ErrInvalidTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, customErrors.ErrTemplateNotFound), errors.Is(err, customErrors.ErrNoRowsFindToDelete):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
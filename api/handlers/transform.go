@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type PhotoTransformUseCase interface {
+	Transform(id string, params dtos.TransformParams, signature string, accepted []string) (dtos.DerivedImage, error)
+}
+
+type TransformHandler struct {
+	transformUseCase PhotoTransformUseCase
+	log              *zerolog.Logger
+}
+
+func NewTransformHandler(transformUseCase PhotoTransformUseCase, log *zerolog.Logger) TransformHandler {
+	return TransformHandler{
+		transformUseCase: transformUseCase,
+		log:              log,
+	}
+}
+
+func (h TransformHandler) Transform(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		RespondErr(w, h.log, fmt.Errorf("id is required"), http.StatusBadRequest)
+
+		return
+	}
+
+	params, err := parseTransformParams(r.URL.Query())
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("parseTransformParams() failed: %w", err), http.StatusBadRequest)
+
+		return
+	}
+
+	// format picked by Accept makes response vary by it
+	if params.Format == "" {
+		w.Header().Set("Vary", "Accept")
+	}
+
+	accepted := acceptedMimeTypes(r, []string{utils.MimeTypeJPEG, utils.MimeTypeWebP, utils.MimeTypePNG})
+
+	derived, err := h.transformUseCase.Transform(id, params, r.URL.Query().Get("sig"), accepted)
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("transformUseCase.Transform(): %w", err), transformErrStatusCode(err))
+
+		return
+	}
+
+	RespondImage(w, h.log, derived.MimeType, derived.Data)
+}
+
+// parseTransformParams parses query: ?w=&h=&fit=&format=&q=&filters=sepia:0.5,vintage, empty values are defaulted by usecase
+func parseTransformParams(query url.Values) (dtos.TransformParams, error) {
+	params := dtos.TransformParams{
+		Fit:    query.Get("fit"),
+		Format: query.Get("format"),
+	}
+
+	if value := query.Get("filters"); value != "" {
+		params.Filters = strings.Split(value, ",")
+	}
+
+	for key, target := range map[string]*int{
+		"w": &params.Width,
+		"h": &params.Height,
+		"q": &params.Quality,
+	} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return params, fmt.Errorf("invalid %s: %w", key, err)
+		}
+
+		*target = parsed
+	}
+
+	return params, nil
+}
+
+func transformErrStatusCode(err error) int {
+	switch {
+	case errors.Is(err, customErrors.ErrInvalidTransform):
+		return http.StatusBadRequest
+	case errors.Is(err, customErrors.ErrInvalidSignature):
+		return http.StatusForbidden
+	case errors.Is(err, customErrors.ErrPhotoDeleted):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+type PhotoUploadUseCase interface {
+	CreateUpload() (dtos.Upload, error)
+	Upload(id, expires, signature string, data io.Reader) error
+	Finalize(id, expires, signature string) error
+}
+
+type UploadHandler struct {
+	uploadUseCase PhotoUploadUseCase
+	log           *zerolog.Logger
+}
+
+func NewUploadHandler(uploadUseCase PhotoUploadUseCase, log *zerolog.Logger) UploadHandler {
+	return UploadHandler{
+		uploadUseCase: uploadUseCase,
+		log:           log,
+	}
+}
+
+func (h UploadHandler) Create(w http.ResponseWriter, _ *http.Request) {
+	upload, err := h.uploadUseCase.CreateUpload()
+	if err != nil {
+		RespondErr(w, h.log, fmt.Errorf("uploadUseCase.CreateUpload(): %w", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	Respond(w, h.log, upload)
+}
+
+func (h UploadHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "uploadID")
+	query := r.URL.Query()
+
+	if err := h.uploadUseCase.Upload(id, query.Get("expires"), query.Get("signature"), r.Body); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("uploadUseCase.Upload(): %w", err), uploadErrStatusCode(err))
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
+
+func (h UploadHandler) Finalize(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "uploadID")
+	query := r.URL.Query()
+
+	if err := h.uploadUseCase.Finalize(id, query.Get("expires"), query.Get("signature")); err != nil {
+		RespondErr(w, h.log, fmt.Errorf("uploadUseCase.Finalize(): %w", err), uploadErrStatusCode(err))
+
+		return
+	}
+
+	RespondStatusOk(w, h.log)
+}
+
+func uploadErrStatusCode(err error) int {
+	switch {
+	case errors.Is(err, customErrors.ErrInvalidSignature), errors.Is(err, customErrors.ErrUploadExpired):
+		return http.StatusForbidden
+	case errors.Is(err, customErrors.ErrUploadNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, customErrors.ErrUploadFinalized):
+		return http.StatusConflict
+	case errors.Is(err, customErrors.ErrPhotoTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, customErrors.ErrUnsupportedPhotoType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -14,6 +14,7 @@ import (
 	md "test-task-photo-booth/api/middleware"
 	"test-task-photo-booth/api/routes"
 	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/src/config"
 )
 
 const RequestTimeout = 60
@@ -21,14 +22,16 @@ const RequestTimeout = 60
 var skipPaths = []string{"/ping"}
 
 type Router struct {
+	configs        config.Configs
 	postgresClient *pgxpool.Pool
 	rabbitClient   *rabbitmq.RabbitMqClient
 	log            *zerolog.Logger
 }
 
 // NewRouter defines new router instance
-func NewRouter(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger) *chi.Mux {
+func NewRouter(configs config.Configs, postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger) *chi.Mux {
 	router := &Router{
+		configs:        configs,
 		postgresClient: postgresClient,
 		rabbitClient:   rabbitClient,
 		log:            log,
@@ -63,7 +66,7 @@ func NewRouter(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClie
 	r.Get("/health-check", handlers.HealthCheck)
 
 	//Mounts /api routes to main router
-	r.Mount("/api", routes.API(router.postgresClient, router.rabbitClient, router.log))
+	r.Mount("/api", routes.API(router.configs, router.postgresClient, router.rabbitClient, router.log))
 
 	return r
 }
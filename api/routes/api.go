@@ -6,13 +6,38 @@ import (
 	"github.com/rs/zerolog"
 
 	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/src/config"
 )
 
-func API(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger) chi.Router {
+func API(configs config.Configs, postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger) chi.Router {
 	r := chi.NewRouter()
 
 	r.Route("/photo", func(r chi.Router) {
-		photo(postgresClient, rabbitClient, log, r)
+		r.Route("/uploads", func(r chi.Router) {
+			uploads(configs, rabbitClient, log, r)
+		})
+
+		photo(configs, postgresClient, rabbitClient, log, r)
+	})
+
+	r.Route("/strips", func(r chi.Router) {
+		strips(postgresClient, rabbitClient, log, r)
+	})
+
+	r.Route("/boomerangs", func(r chi.Router) {
+		boomerangs(postgresClient, rabbitClient, log, r)
+	})
+
+	r.Route("/backgrounds", func(r chi.Router) {
+		backgrounds(postgresClient, log, r)
+	})
+
+	r.Route("/templates", func(r chi.Router) {
+		templates(postgresClient, log, r)
+	})
+
+	r.Route("/admin/photos/reprocess", func(r chi.Router) {
+		reprocess(postgresClient, rabbitClient, log, r)
 	})
 
 	return r
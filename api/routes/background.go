@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+)
+
+func backgrounds(postgresClient *pgxpool.Pool, log *zerolog.Logger, r chi.Router) {
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+
+	backgroundUseCase := usecases.NewBackgroundUseCase(backgroundCollection, log)
+
+	backgroundHandler := handlers.NewBackgroundHandler(backgroundUseCase, log)
+
+	r.Post("/", backgroundHandler.Create)
+	r.Get("/", backgroundHandler.GetAll)
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", backgroundHandler.GetByID)
+		r.Delete("/", backgroundHandler.Delete)
+	})
+}
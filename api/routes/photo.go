@@ -8,27 +8,39 @@ import (
 	"test-task-photo-booth/api/adapters/db/postgres"
 	"test-task-photo-booth/api/adapters/queue/rmq"
 	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/src/config"
 
 	"test-task-photo-booth/api/handlers"
 	"test-task-photo-booth/api/usecases"
 )
 
-func photo(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger, r chi.Router) {
+func photo(configs config.Configs, postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger, r chi.Router) {
 	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
 	photoQueue := rmq.NewPhotoProducer(rabbitClient.Conn, rabbitClient.PhotoQueue, log)
 
 	photoUseCase := usecases.NewPhotoUseCase(photoCollection, log)
-	photoPublishUseCase := usecases.NewPhotoPublishUseCase(photoQueue, log)
+	photoPublishUseCase := usecases.NewPhotoPublishUseCase(photoQueue, backgroundCollection, templateCollection, log)
 
 	photoHandler := handlers.NewPhotoHandler(photoUseCase, photoPublishUseCase, log)
+	transformHandler := newTransformHandler(configs, postgresClient, log)
 
 	r.Post("/", photoHandler.Create)
+	r.Post("/import", photoHandler.Import)
+	r.Post("/best", photoHandler.GetBestOfBurst)
 
 	r.Get("/", photoHandler.GetAllPhotos)
 
 	r.Route("/{id}", func(r chi.Router) {
 		r.Get("/", photoHandler.GetByID)
 		r.Delete("/", photoHandler.Delete)
+		r.Get("/metadata", photoHandler.GetMetadata)
+		r.Get("/attribution", photoHandler.GetAttribution)
+		r.Get("/variants", photoHandler.GetVariants)
+		r.Get("/variants/{name}", photoHandler.GetVariantImage)
+		r.Get("/similar", photoHandler.GetSimilar)
+		r.Get("/transform", transformHandler.Transform)
 	})
 
 }
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/adapters/queue/rmq"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+	"test-task-photo-booth/pkg/clients/rabbitmq"
+)
+
+func reprocess(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger, r chi.Router) {
+	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
+	reprocessJobCollection := postgres.NewReprocessJobStoragePG(postgresClient, log)
+
+	// Reprocess jobs have own queue, so they don't wait behind or delay live uploads
+	reprocessQueue := rmq.NewPhotoProducer(rabbitClient.Conn, rabbitClient.ReprocessQueue, log)
+
+	reprocessUseCase := usecases.NewReprocessUseCase(photoCollection, reprocessJobCollection, reprocessQueue, log)
+
+	reprocessHandler := handlers.NewReprocessHandler(reprocessUseCase, log)
+
+	r.Post("/", reprocessHandler.Start)
+	r.Get("/{id}", reprocessHandler.GetJob)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/adapters/queue/rmq"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+	"test-task-photo-booth/pkg/clients/rabbitmq"
+)
+
+func strips(postgresClient *pgxpool.Pool, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger, r chi.Router) {
+	photoQueue := rmq.NewPhotoProducer(rabbitClient.Conn, rabbitClient.PhotoQueue, log)
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
+
+	photoPublishUseCase := usecases.NewPhotoPublishUseCase(photoQueue, backgroundCollection, templateCollection, log)
+
+	stripHandler := handlers.NewStripHandler(photoPublishUseCase, log)
+
+	r.Post("/", stripHandler.Create)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+)
+
+func templates(postgresClient *pgxpool.Pool, log *zerolog.Logger, r chi.Router) {
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
+
+	templateUseCase := usecases.NewTemplateUseCase(templateCollection, log)
+
+	templateHandler := handlers.NewTemplateHandler(templateUseCase, log)
+
+	r.Post("/", templateHandler.Create)
+	r.Get("/", templateHandler.GetAll)
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", templateHandler.GetByID)
+		r.Put("/", templateHandler.Update)
+		r.Delete("/", templateHandler.Delete)
+	})
+}
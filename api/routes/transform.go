@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/api/adapters/cache/memory"
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/adapters/storage/disk"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+	"test-task-photo-booth/src/config"
+)
+
+const (
+	configTransformCacheDir         = "transform.cache.dir"
+	configTransformCacheMemoryBytes = "transform.cache.memoryBytes"
+	configTransformCacheDiskBytes   = "transform.cache.diskBytes"
+
+	defaultTransformCacheDir         = "./cache/derived"
+	defaultTransformCacheMemoryBytes = 64 << 20
+	defaultTransformCacheDiskBytes   = 1 << 30
+)
+
+func newTransformHandler(configs config.Configs, postgresClient *pgxpool.Pool, log *zerolog.Logger) handlers.TransformHandler {
+	viper.SetDefault(configTransformCacheDir, defaultTransformCacheDir)
+	viper.SetDefault(configTransformCacheMemoryBytes, defaultTransformCacheMemoryBytes)
+	viper.SetDefault(configTransformCacheDiskBytes, defaultTransformCacheDiskBytes)
+
+	diskCache, err := disk.NewDerivedCacheDisk(
+		viper.GetString(configTransformCacheDir),
+		viper.GetInt64(configTransformCacheDiskBytes),
+		log,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("disk.NewDerivedCacheDisk() failed")
+	}
+
+	memoryCache := memory.NewDerivedCacheMemory(viper.GetInt64(configTransformCacheMemoryBytes))
+
+	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
+
+	transformUseCase, err := usecases.NewPhotoTransformUseCase(
+		photoCollection,
+		backgroundCollection,
+		templateCollection,
+		memoryCache,
+		diskCache,
+		configs.SecretsConf.TransformSecret,
+		log,
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("usecases.NewPhotoTransformUseCase() failed")
+	}
+
+	return handlers.NewTransformHandler(transformUseCase, log)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/api/adapters/queue/rmq"
+	"test-task-photo-booth/api/adapters/storage/disk"
+	"test-task-photo-booth/api/handlers"
+	"test-task-photo-booth/api/usecases"
+	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/src/config"
+	"test-task-photo-booth/src/entities"
+)
+
+func uploads(configs config.Configs, rabbitClient *rabbitmq.RabbitMqClient, log *zerolog.Logger, r chi.Router) {
+	// Secret isn't required by config, consumer and hot folder watcher never sign urls
+	if configs.SecretsConf.UploadSecret == "" {
+		log.Fatal().Msg("SERVICE_UPLOADSECRET is required for direct uploads")
+	}
+
+	viper.SetDefault(entities.ConfigUploadsDir, entities.DefaultUploadsDir)
+
+	uploadStorage, err := disk.NewUploadStorageDisk(viper.GetString(entities.ConfigUploadsDir), log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("disk.NewUploadStorageDisk() failed")
+	}
+
+	photoQueue := rmq.NewPhotoProducer(rabbitClient.Conn, rabbitClient.PhotoQueue, log)
+
+	uploadUseCase := usecases.NewPhotoUploadUseCase(uploadStorage, photoQueue, configs.SecretsConf.UploadSecret, log)
+
+	uploadHandler := handlers.NewUploadHandler(uploadUseCase, log)
+
+	r.Post("/", uploadHandler.Create)
+
+	r.Route("/{uploadID}", func(r chi.Router) {
+		r.Put("/", uploadHandler.Upload)
+		r.Post("/finalize", uploadHandler.Finalize)
+	})
+}
@@ -0,0 +1,214 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/exif"
+	"test-task-photo-booth/pkg/icc"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/pkg/webp"
+	"test-task-photo-booth/pkg/xmp"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configAttribution = "attribution"
+)
+
+// attributionSettings are author information of all photos, events may override any field
+type attributionSettings struct {
+	defaults dtos.Attribution
+	events   map[string]dtos.Attribution
+}
+
+func loadAttributionSettings() (attributionSettings, error) {
+	var defaults dtos.Attribution
+	if err := viper.UnmarshalKey(configAttribution, &defaults); err != nil {
+		return attributionSettings{}, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	// viper lowercases map keys, so event names are matched case-insensitively
+	events := make(map[string]eventProfile)
+	if err := viper.UnmarshalKey(configEvents, &events); err != nil {
+		return attributionSettings{}, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	eventAttributions := make(map[string]dtos.Attribution, len(events))
+	for name, event := range events {
+		eventAttributions[name] = event.Attribution
+	}
+
+	return attributionSettings{defaults: defaults, events: eventAttributions}, nil
+}
+
+// resolve returns attribution of photo uploaded for event
+func (a attributionSettings) resolve(event string) dtos.Attribution {
+	attribution := a.defaults
+
+	override, ok := a.events[strings.ToLower(event)]
+	if event == "" || !ok {
+		return attribution
+	}
+
+	if override.Creator != "" {
+		attribution.Creator = override.Creator
+	}
+
+	if override.Copyright != "" {
+		attribution.Copyright = override.Copyright
+	}
+
+	if override.Credit != "" {
+		attribution.Credit = override.Credit
+	}
+
+	return attribution
+}
+
+// variantMetadata is embedded into every JPEG and WebP output: attribution as EXIF and XMP, ICC profile in preserve color mode
+type variantMetadata struct {
+	exif       []byte // TIFF data of EXIF APP1 segment
+	xmp        []byte
+	iccProfile []byte
+}
+
+func newVariantMetadata(attribution dtos.Attribution, iccProfile []byte) variantMetadata {
+	metadata := variantMetadata{iccProfile: iccProfile}
+
+	if attribution == (dtos.Attribution{}) {
+		return metadata
+	}
+
+	// Variants never carry source EXIF, so it's written from scratch
+	attributionExif := &exif.Exif{ByteOrder: binary.BigEndian}
+	if attribution.Creator != "" {
+		attributionExif.IFD0 = attributionExif.IFD0.Set(exif.ASCIIEntry(exif.TagArtist, attribution.Creator))
+	}
+
+	if attribution.Copyright != "" {
+		attributionExif.IFD0 = attributionExif.IFD0.Set(exif.ASCIIEntry(exif.TagCopyright, attribution.Copyright))
+	}
+
+	if len(attributionExif.IFD0) > 0 {
+		metadata.exif = attributionExif.Bytes()
+	}
+
+	metadata.xmp = xmp.Packet{
+		Creator: attribution.Creator,
+		Rights:  attribution.Copyright,
+		Credit:  attribution.Credit,
+	}.Bytes()
+
+	return metadata
+}
+
+// size is number of bytes embedding adds to JPEG, it counts towards profile byte budget
+func (m variantMetadata) size() int {
+	return len(m.exif) + len(m.xmp) + len(m.iccProfile)
+}
+
+// embed writes metadata into JPEG encoded by image/jpeg
+func (m variantMetadata) embed(data []byte) ([]byte, error) {
+	var err error
+
+	if m.exif != nil {
+		data, err = exif.ReplaceJPEG(data, m.exif)
+		if err != nil {
+			return nil, fmt.Errorf("exif.ReplaceJPEG() failed: %w", err)
+		}
+	}
+
+	if m.xmp != nil {
+		data, err = xmp.ReplaceJPEG(data, m.xmp)
+		if err != nil {
+			return nil, fmt.Errorf("xmp.ReplaceJPEG() failed: %w", err)
+		}
+	}
+
+	if m.iccProfile != nil {
+		data, err = icc.EmbedJPEG(data, m.iccProfile)
+		if err != nil {
+			return nil, fmt.Errorf("icc.EmbedJPEG() failed: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// embedWebP writes metadata into WebP encoded by utils.EncodeWebP
+func (m variantMetadata) embedWebP(data []byte) ([]byte, error) {
+	embedded, err := webp.Embed(data, webp.Metadata{ICCProfile: m.iccProfile, Exif: m.exif, XMP: m.xmp})
+	if err != nil {
+		return nil, fmt.Errorf("webp.Embed() failed: %w", err)
+	}
+
+	return embedded, nil
+}
+
+// GetAttribution reads attribution embedded into photo variant of profile, or into 75% variant when variant is empty
+func (p PhotoUseCase) GetAttribution(id, variant string) (dtos.Attribution, error) {
+	ctx := context.Background()
+
+	var encoded string
+
+	if variant != "" {
+		photoVariant, err := p.db.FindVariant(ctx, id, variant, utils.MimeTypeJPEG)
+		if err != nil {
+			return dtos.Attribution{}, fmt.Errorf("db.FindVariant(): %w", err)
+		}
+
+		encoded = photoVariant.Data
+	} else {
+		photoDB, err := p.db.FindOne(ctx, id)
+		if err != nil {
+			return dtos.Attribution{}, fmt.Errorf("db.FindOne(): %w", err)
+		}
+
+		encoded = photoDB.Data75
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return dtos.Attribution{}, fmt.Errorf("base64.DecodeString() failed: %w", err)
+	}
+
+	return readAttribution(data), nil
+}
+
+// readAttribution reads XMP of JPEG, fields missing there are taken from EXIF
+func readAttribution(data []byte) dtos.Attribution {
+	var attribution dtos.Attribution
+
+	if packet, err := xmp.ExtractJPEG(data); err == nil {
+		if parsed, err := xmp.Parse(packet); err == nil {
+			attribution = dtos.Attribution{Creator: parsed.Creator, Copyright: parsed.Rights, Credit: parsed.Credit}
+		}
+	}
+
+	tiff, err := exif.ExtractJPEG(data)
+	if err != nil {
+		return attribution
+	}
+
+	exifData, err := exif.Parse(tiff)
+	if err != nil {
+		return attribution
+	}
+
+	if attribution.Creator == "" {
+		attribution.Creator, _ = exifData.StringTag(exifData.IFD0, exif.TagArtist)
+	}
+
+	if attribution.Copyright == "" {
+		attribution.Copyright, _ = exifData.StringTag(exifData.IFD0, exif.TagCopyright)
+	}
+
+	return attribution
+}
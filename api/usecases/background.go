@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// BackgroundUseCase manages backgrounds library for chroma key
+type BackgroundUseCase struct {
+	db  clients.BackgroundStorage
+	log *zerolog.Logger
+}
+
+func NewBackgroundUseCase(storage clients.BackgroundStorage, l *zerolog.Logger) BackgroundUseCase {
+	return BackgroundUseCase{
+		db:  storage,
+		log: l,
+	}
+}
+
+// Create stores JPEG or PNG background, data is b64 or data URI
+func (b BackgroundUseCase) Create(name, data string) (dtos.Background, error) {
+	ctx := context.Background()
+
+	decoded, mimeType, err := utils.DecodeB64DataURI(data)
+	if err != nil {
+		return dtos.Background{}, fmt.Errorf("utils.DecodeB64DataURI() failed: %w", err)
+	}
+
+	if !utils.IsSupportedPhotoMimeType(mimeType) {
+		return dtos.Background{}, fmt.Errorf("%w: %s", customErrors.ErrUnsupportedPhotoType, mimeType)
+	}
+
+	img, _, err := utils.DecodeImage(decoded)
+	if err != nil {
+		return dtos.Background{}, fmt.Errorf("utils.DecodeImage() failed: %w", err)
+	}
+
+	background := dtos.Background{
+		Name:     name,
+		MimeType: mimeType,
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		Data:     base64.StdEncoding.EncodeToString(decoded),
+	}
+
+	if err := b.db.Create(ctx, &background); err != nil {
+		return dtos.Background{}, fmt.Errorf("db.Create(): %w", err)
+	}
+
+	// data is echoed back only by GetByID
+	background.Data = ""
+
+	return background, nil
+}
+
+// GetAll lists backgrounds without data
+func (b BackgroundUseCase) GetAll() ([]dtos.Background, error) {
+	ctx := context.Background()
+	backgrounds, err := b.db.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindAll(): %w", err)
+	}
+
+	return backgrounds, nil
+}
+
+func (b BackgroundUseCase) GetByID(id string) (dtos.Background, error) {
+	ctx := context.Background()
+	background, err := b.db.FindOne(ctx, id)
+	if err != nil {
+		return dtos.Background{}, fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	if background.IsDeleted {
+		return dtos.Background{}, customErrors.ErrBackgroundNotFound
+	}
+
+	return background, nil
+}
+
+func (b BackgroundUseCase) Delete(id string) error {
+	ctx := context.Background()
+	if err := b.db.Delete(ctx, id); err != nil {
+		return fmt.Errorf("db.Delete(): %w", err)
+	}
+
+	return nil
+}
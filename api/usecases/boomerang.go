@@ -0,0 +1,215 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configBoomerangWidth     = "boomerang.width"
+	configBoomerangMaxWidth  = "boomerang.maxWidth"
+	configBoomerangDelayMs   = "boomerang.delayMs"
+	configBoomerangMaxFrames = "boomerang.maxFrames"
+	configBoomerangColors    = "boomerang.colors"
+	configBoomerangDither    = "boomerang.dither"
+)
+
+const (
+	defaultBoomerangWidth     = 480
+	defaultBoomerangMaxWidth  = 1080
+	defaultBoomerangDelayMs   = 100
+	defaultBoomerangMaxFrames = 20
+	defaultBoomerangColors    = 256
+	defaultBoomerangDither    = true
+
+	minBoomerangFrames  = 2
+	minBoomerangDelayMs = 20
+	maxBoomerangDelayMs = 2000
+)
+
+type boomerangOptions struct {
+	width     int
+	maxWidth  int
+	delayMs   int
+	maxFrames int
+	colors    int
+	dither    bool
+}
+
+func loadBoomerangOptions() boomerangOptions {
+	viper.SetDefault(configBoomerangWidth, defaultBoomerangWidth)
+	viper.SetDefault(configBoomerangMaxWidth, defaultBoomerangMaxWidth)
+	viper.SetDefault(configBoomerangDelayMs, defaultBoomerangDelayMs)
+	viper.SetDefault(configBoomerangMaxFrames, defaultBoomerangMaxFrames)
+	viper.SetDefault(configBoomerangColors, defaultBoomerangColors)
+	viper.SetDefault(configBoomerangDither, defaultBoomerangDither)
+
+	return boomerangOptions{
+		width:     viper.GetInt(configBoomerangWidth),
+		maxWidth:  viper.GetInt(configBoomerangMaxWidth),
+		delayMs:   viper.GetInt(configBoomerangDelayMs),
+		maxFrames: viper.GetInt(configBoomerangMaxFrames),
+		colors:    viper.GetInt(configBoomerangColors),
+		dither:    viper.GetBool(configBoomerangDither),
+	}
+}
+
+// AddBoomerangInQueue validates boomerang and adds job for consumer to animate it
+func (p PhotoPublishUseCase) AddBoomerangInQueue(boomerang *dtos.Boomerang) error {
+	if err := validateBoomerang(boomerang, loadBoomerangOptions()); err != nil {
+		return fmt.Errorf("validateBoomerang() failed: %w", err)
+	}
+
+	if err := p.checkReferences(boomerang.Options); err != nil {
+		return fmt.Errorf("p.checkReferences() failed: %w", err)
+	}
+
+	message := &dtos.PhotoMessage{
+		Type:      entities.PhotoMessageBoomerang,
+		Boomerang: boomerang,
+	}
+
+	if err := p.queue.Publish(message); err != nil {
+		return fmt.Errorf("error adding boomerang to queue: %v", err)
+	}
+
+	return nil
+}
+
+// CreateBoomerang animates frames into GIF and creates it as new photo, variants are rendered from first frame
+func (p PhotoConsumeUseCase) CreateBoomerang(boomerang *dtos.Boomerang) (dtos.Photo, error) {
+	if err := validateBoomerang(boomerang, p.boomerang); err != nil {
+		return dtos.Photo{}, fmt.Errorf("validateBoomerang() failed: %w", err)
+	}
+
+	frames, err := p.boomerangFrames(boomerang)
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.boomerangFrames() failed: %w", err)
+	}
+
+	options := utils.BoomerangOptions{
+		Width:   p.boomerang.width,
+		DelayMs: p.boomerang.delayMs,
+		Colors:  p.boomerang.colors,
+		Dither:  p.boomerang.dither,
+	}
+
+	if boomerang.Width > 0 {
+		options.Width = boomerang.Width
+	}
+
+	if boomerang.DelayMs > 0 {
+		options.DelayMs = boomerang.DelayMs
+	}
+
+	data, err := utils.EncodeGIF(utils.Boomerang(frames, options))
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("utils.EncodeGIF() failed: %w", err)
+	}
+
+	photo := dtos.Photo{Data: base64.StdEncoding.EncodeToString(data), Options: boomerang.Options}
+	if err := p.Create(&photo); err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.Create(): %w", err)
+	}
+
+	return photo, nil
+}
+
+func (p PhotoConsumeUseCase) boomerangFrames(boomerang *dtos.Boomerang) ([]image.Image, error) {
+	if len(boomerang.PhotoIDs) == 0 {
+		return decodeBoomerangGIF(boomerang.Data)
+	}
+
+	ctx := context.Background()
+	frames := make([]image.Image, 0, len(boomerang.PhotoIDs))
+
+	for _, id := range boomerang.PhotoIDs {
+		photoDB, err := p.db.FindOne(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("db.FindOne(): %w", err)
+		}
+
+		img, colorProfile, err := decodeStoredOriginal(photoDB)
+		if err != nil {
+			return nil, fmt.Errorf("decodeStoredOriginal() failed: %w", err)
+		}
+
+		// Photos of different color spaces are combined in sRGB
+		img = toSRGB(img, colorProfile)
+
+		// Frames look same way as variants of their photos
+		img, err = p.stages.apply(ctx, img, photoDB.Options)
+		if err != nil {
+			return nil, fmt.Errorf("p.stages.apply() failed: %w", err)
+		}
+
+		frames = append(frames, img)
+	}
+
+	return frames, nil
+}
+
+func validateBoomerang(boomerang *dtos.Boomerang, options boomerangOptions) error {
+	frameCount := len(boomerang.PhotoIDs)
+
+	switch {
+	case frameCount > 0 && boomerang.Data != "":
+		return fmt.Errorf("%w: either photoIds or data is expected", customErrors.ErrInvalidBoomerang)
+	case frameCount == 0:
+		frames, err := decodeBoomerangGIF(boomerang.Data)
+		if err != nil {
+			return fmt.Errorf("%w: %w", customErrors.ErrInvalidBoomerang, err)
+		}
+
+		frameCount = len(frames)
+	}
+
+	if frameCount < minBoomerangFrames || frameCount > options.maxFrames {
+		return fmt.Errorf("%w: boomerang takes from %d to %d frames, got %d",
+			customErrors.ErrInvalidBoomerang, minBoomerangFrames, options.maxFrames, frameCount)
+	}
+
+	if boomerang.Width < 0 || boomerang.Width > options.maxWidth {
+		return fmt.Errorf("%w: width must be from 0 to %d", customErrors.ErrInvalidBoomerang, options.maxWidth)
+	}
+
+	if boomerang.DelayMs != 0 && (boomerang.DelayMs < minBoomerangDelayMs || boomerang.DelayMs > maxBoomerangDelayMs) {
+		return fmt.Errorf("%w: delayMs must be from %d to %d", customErrors.ErrInvalidBoomerang, minBoomerangDelayMs, maxBoomerangDelayMs)
+	}
+
+	if _, err := filters.ParseAll(boomerang.Options.Filters); err != nil {
+		return fmt.Errorf("%w: %w", customErrors.ErrInvalidBoomerang, err)
+	}
+
+	return nil
+}
+
+// decodeBoomerangGIF decodes frames of uploaded animated GIF
+func decodeBoomerangGIF(data string) ([]image.Image, error) {
+	decoded, mimeType, err := utils.DecodeB64DataURI(data)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeB64DataURI() failed: %w", err)
+	}
+
+	if mimeType != utils.MimeTypeGIF {
+		return nil, fmt.Errorf("%w: %s", customErrors.ErrUnsupportedPhotoType, mimeType)
+	}
+
+	frames, err := utils.DecodeGIFFrames(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeGIFFrames() failed: %w", err)
+	}
+
+	return frames, nil
+}
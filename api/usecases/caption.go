@@ -0,0 +1,205 @@
+package usecases
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configCaptionsFont         = "captions.font"
+	configCaptionsSize         = "captions.size"
+	configCaptionsColor        = "captions.color"
+	configCaptionsOutlineColor = "captions.outlineColor"
+	configCaptionsOutline      = "captions.outline"
+	configCaptionsPosition     = "captions.position"
+	configCaptionsDateFormat   = "captions.dateFormat"
+	configCaptionsFonts        = "captions.fonts"
+)
+
+const (
+	defaultCaptionFont         = utils.CaptionFontRegular
+	defaultCaptionSize         = 0.05
+	defaultCaptionColor        = "#FFFFFF"
+	defaultCaptionOutlineColor = "#000000"
+	defaultCaptionOutline      = 0.08
+	defaultCaptionPosition     = utils.CaptionBottom
+	defaultCaptionDateFormat   = "02.01.2006"
+
+	captionRegion = "caption"
+)
+
+// captionFontFile is font loaded from TrueType/OpenType file, configured in captions.fonts
+type captionFontFile struct {
+	Name string `mapstructure:"name"`
+	Path string `mapstructure:"path"`
+}
+
+type captionDefaults struct {
+	dtos.CaptionOptions
+	dateFormat string
+	fonts      map[string]utils.CaptionFont
+}
+
+func loadCaptionDefaults() (captionDefaults, error) {
+	viper.SetDefault(configCaptionsFont, defaultCaptionFont)
+	viper.SetDefault(configCaptionsSize, defaultCaptionSize)
+	viper.SetDefault(configCaptionsColor, defaultCaptionColor)
+	viper.SetDefault(configCaptionsOutlineColor, defaultCaptionOutlineColor)
+	viper.SetDefault(configCaptionsOutline, defaultCaptionOutline)
+	viper.SetDefault(configCaptionsPosition, defaultCaptionPosition)
+	viper.SetDefault(configCaptionsDateFormat, defaultCaptionDateFormat)
+
+	files := make([]captionFontFile, 0)
+	if err := viper.UnmarshalKey(configCaptionsFonts, &files); err != nil {
+		return captionDefaults{}, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	fonts := make(map[string]utils.CaptionFont, len(files))
+
+	for _, file := range files {
+		if _, ok := utils.BuiltinCaptionFont(file.Name); ok || file.Name == "" {
+			return captionDefaults{}, fmt.Errorf("invalid caption font name: %q", file.Name)
+		}
+
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return captionDefaults{}, fmt.Errorf("caption font %s: os.ReadFile() failed: %w", file.Name, err)
+		}
+
+		fonts[file.Name], err = utils.ParseCaptionFont(data)
+		if err != nil {
+			return captionDefaults{}, fmt.Errorf("caption font %s: %w", file.Name, err)
+		}
+	}
+
+	defaults := captionDefaults{
+		CaptionOptions: dtos.CaptionOptions{
+			Font:         viper.GetString(configCaptionsFont),
+			Size:         viper.GetFloat64(configCaptionsSize),
+			Color:        viper.GetString(configCaptionsColor),
+			OutlineColor: viper.GetString(configCaptionsOutlineColor),
+			Outline:      viper.GetFloat64(configCaptionsOutline),
+			Position:     viper.GetString(configCaptionsPosition),
+			Region:       captionRegion,
+		},
+		dateFormat: viper.GetString(configCaptionsDateFormat),
+		fonts:      fonts,
+	}
+
+	// defaults are checked once, so broken config fails on start instead of every photo
+	if _, err := defaults.options(dtos.CaptionOptions{}, image.Rect(0, 0, 1, 1), nil); err != nil {
+		return captionDefaults{}, fmt.Errorf("invalid captions config: %w", err)
+	}
+
+	return defaults, nil
+}
+
+// apply prints caption in template caption region or over whole photo
+func (c captionDefaults) apply(img image.Image, caption dtos.CaptionOptions, event string, template *dtos.Template, date time.Time) (image.Image, error) {
+	options, err := c.options(caption, img.Bounds(), template)
+	if err != nil {
+		return nil, fmt.Errorf("c.options() failed: %w", err)
+	}
+
+	captioned, err := utils.Caption(img, c.text(caption.Text, event, date), options)
+	if err != nil {
+		return nil, fmt.Errorf("utils.Caption() failed: %w", err)
+	}
+
+	return captioned, nil
+}
+
+// text substitutes placeholders, text without them is returned as is
+func (c captionDefaults) text(text, event string, date time.Time) string {
+	return strings.NewReplacer("{date}", date.Format(c.dateFormat), "{event}", event).Replace(text)
+}
+
+// options fills empty caption fields by defaults and converts sizes to pixels of photo
+func (c captionDefaults) options(caption dtos.CaptionOptions, bounds image.Rectangle, template *dtos.Template) (utils.CaptionOptions, error) {
+	if caption.Font == "" {
+		caption.Font = c.Font
+	}
+
+	if caption.Size == 0 {
+		caption.Size = c.Size
+	}
+
+	if caption.Color == "" {
+		caption.Color = c.Color
+	}
+
+	if caption.OutlineColor == "" {
+		caption.OutlineColor = c.OutlineColor
+	}
+
+	if caption.Outline == 0 {
+		caption.Outline = c.Outline
+	}
+
+	if caption.Position == "" {
+		caption.Position = c.Position
+	}
+
+	if caption.Region == "" {
+		caption.Region = c.Region
+	}
+
+	captionFont, ok := c.fonts[caption.Font]
+	if !ok {
+		captionFont, ok = utils.BuiltinCaptionFont(caption.Font)
+	}
+
+	if !ok {
+		return utils.CaptionOptions{}, fmt.Errorf("unknown caption font: %s", caption.Font)
+	}
+
+	if !utils.IsCaptionPositionSupported(caption.Position) {
+		return utils.CaptionOptions{}, fmt.Errorf("unsupported caption position: %s", caption.Position)
+	}
+
+	if caption.Size <= 0 || caption.Size > 1 || caption.Outline < 0 || caption.Outline > 1 {
+		return utils.CaptionOptions{}, fmt.Errorf("caption size and outline must be 0-1")
+	}
+
+	textColor, err := utils.ParseHexColor(caption.Color)
+	if err != nil {
+		return utils.CaptionOptions{}, fmt.Errorf("invalid caption color: %w", err)
+	}
+
+	outlineColor, err := utils.ParseHexColor(caption.OutlineColor)
+	if err != nil {
+		return utils.CaptionOptions{}, fmt.Errorf("invalid caption outline color: %w", err)
+	}
+
+	size := caption.Size * float64(bounds.Dy())
+
+	options := utils.CaptionOptions{
+		Font:         captionFont,
+		Size:         size,
+		Color:        textColor,
+		OutlineColor: outlineColor,
+		Outline:      int(math.Round(size * caption.Outline)),
+		Position:     caption.Position,
+		Margin:       int(math.Round(size / 2)),
+	}
+
+	if template != nil {
+		for _, region := range template.Regions {
+			if region.Name == caption.Region {
+				options.Area = templateRegionRect(region)
+			}
+		}
+	}
+
+	return options, nil
+}
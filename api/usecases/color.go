@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/exif"
+	"test-task-photo-booth/pkg/icc"
+	"test-task-photo-booth/pkg/utils"
+)
+
+// Viper config keys
+const (
+	configColorProfilesMode = "colorProfiles.mode"
+)
+
+// Color profile modes
+const (
+	colorModeConvert  = "convert"  // pixels are converted to sRGB, variants carry no profile
+	colorModePreserve = "preserve" // pixels are kept, source profile is embedded into JPEG variants
+)
+
+// EXIF ColorSpace values
+const (
+	exifColorSpaceSRGB         = 1
+	exifColorSpaceUncalibrated = 0xFFFF
+)
+
+// colorHandling decides how photos with non-sRGB profile are rendered
+type colorHandling struct {
+	mode string
+}
+
+func loadColorHandling() (colorHandling, error) {
+	viper.SetDefault(configColorProfilesMode, colorModeConvert)
+
+	mode := viper.GetString(configColorProfilesMode)
+	if mode != colorModeConvert && mode != colorModePreserve {
+		return colorHandling{}, fmt.Errorf("unsupported color profiles mode: %s", mode)
+	}
+
+	return colorHandling{mode: mode}, nil
+}
+
+// prepare returns image variants are rendered from and profile to embed into JPEG variants, nil when they are sRGB.
+// Profiles which can't be converted, e.g. LUT based ones, are embedded in any mode
+func (c colorHandling) prepare(img image.Image, profile *icc.Profile) (image.Image, []byte) {
+	if profile == nil || profile.IsSRGB() {
+		return img, nil
+	}
+
+	if c.mode == colorModePreserve || !profile.IsConvertible() {
+		return img, profile.Data
+	}
+
+	return profile.ToSRGB(img), nil
+}
+
+// toSRGB converts image to sRGB for outputs which can't carry profile, e.g. composites of several photos
+func toSRGB(img image.Image, profile *icc.Profile) image.Image {
+	if profile == nil {
+		return img
+	}
+
+	return profile.ToSRGB(img)
+}
+
+// readColorProfile returns embedded ICC profile of photo, nil if it has none.
+// Broken profiles are ignored the same way browsers do, photo is treated as sRGB
+func readColorProfile(data []byte, mimeType string) *icc.Profile {
+	var (
+		profileData []byte
+		err         error
+	)
+
+	switch mimeType {
+	case utils.MimeTypeJPEG:
+		profileData, err = icc.ExtractJPEG(data)
+	case utils.MimeTypePNG:
+		profileData, err = icc.ExtractPNG(data)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	profile, err := icc.Parse(profileData)
+	if err != nil || profile.ColorSpace != icc.ColorSpaceRGB {
+		return nil
+	}
+
+	return profile
+}
+
+// colorSpaceName names source color space of photo by profile description, or by EXIF when photo has no profile
+func colorSpaceName(profile *icc.Profile, exifData *exif.Exif) *string {
+	if profile != nil && profile.Description != "" {
+		return &profile.Description
+	}
+
+	if exifData == nil {
+		return nil
+	}
+
+	entry, ok := exifData.ExifIFD.Find(exif.TagColorSpace)
+	if !ok {
+		return nil
+	}
+
+	value, ok := exifData.Uint(entry)
+	if !ok {
+		return nil
+	}
+
+	var name string
+
+	switch value {
+	case exifColorSpaceSRGB:
+		name = "sRGB"
+	case exifColorSpaceUncalibrated:
+		name = "Uncalibrated"
+	default:
+		return nil
+	}
+
+	return &name
+}
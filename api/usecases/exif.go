@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"test-task-photo-booth/pkg/exif"
+	"test-task-photo-booth/pkg/utils"
+)
+
+// Viper config keys
+const (
+	configExifStripOriginal = "exif.stripOriginal"
+)
+
+// malformedExifTag is recorded when EXIF couldn't be parsed and whole segment was dropped
+const malformedExifTag = "Exif"
+
+type preparedOriginal struct {
+	data            []byte     // photo to store as original
+	exif            *exif.Exif // EXIF before stripping, nil if photo has none
+	orientation     int
+	removedExifTags []string
+}
+
+// prepareOriginal strips EXIF tags of original photo according to stripMode.
+// Variants are re-encoded and never carry source EXIF, so only original needs stripping
+func prepareOriginal(data []byte, mimeType, stripMode string) (preparedOriginal, error) {
+	original := preparedOriginal{
+		data:            data,
+		orientation:     exif.OrientationNormal,
+		removedExifTags: make([]string, 0),
+	}
+
+	if mimeType != utils.MimeTypeJPEG {
+		return original, nil
+	}
+
+	tiff, err := exif.ExtractJPEG(data)
+	if err != nil {
+		if errors.Is(err, exif.ErrNoExif) {
+			return original, nil
+		}
+
+		return original, fmt.Errorf("exif.ExtractJPEG() failed: %w", err)
+	}
+
+	exifData, err := exif.Parse(tiff)
+	if err != nil {
+		if stripMode == exif.StripNone {
+			return original, nil
+		}
+
+		// Malformed EXIF may still hold location, so it's dropped as a whole
+		stripped, err := exif.ReplaceJPEG(data, nil)
+		if err != nil {
+			return original, fmt.Errorf("exif.ReplaceJPEG() failed: %w", err)
+		}
+
+		original.data = stripped
+		original.removedExifTags = append(original.removedExifTags, malformedExifTag)
+
+		return original, nil
+	}
+
+	original.exif = exifData
+	original.orientation = exifData.Orientation()
+
+	// Strip replaces tag lists, so shallow copy keeps parsed EXIF intact for metadata
+	strippedExif := *exifData
+
+	original.removedExifTags = strippedExif.Strip(stripMode)
+	if len(original.removedExifTags) == 0 {
+		return original, nil
+	}
+
+	stripped, err := exif.ReplaceJPEG(data, strippedExif.Bytes())
+	if err != nil {
+		return original, fmt.Errorf("exif.ReplaceJPEG() failed: %w", err)
+	}
+
+	original.data = stripped
+
+	return original, nil
+}
+
+// readOrientation returns EXIF orientation of stored original, originals keep orientation tag in every strip mode
+func readOrientation(data []byte, mimeType string) int {
+	if mimeType != utils.MimeTypeJPEG {
+		return exif.OrientationNormal
+	}
+
+	tiff, err := exif.ExtractJPEG(data)
+	if err != nil {
+		return exif.OrientationNormal
+	}
+
+	exifData, err := exif.Parse(tiff)
+	if err != nil {
+		return exif.OrientationNormal
+	}
+
+	return exifData.Orientation()
+}
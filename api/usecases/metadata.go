@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"image"
+
+	"test-task-photo-booth/pkg/exif"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// extractMetadata builds metadata record from EXIF of original, exifData may be nil
+func extractMetadata(exifData *exif.Exif, img image.Image) *dtos.PhotoMetadata {
+	metadata := &dtos.PhotoMetadata{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	if exifData == nil {
+		return metadata
+	}
+
+	if capturedAt, ok := exifData.DateTimeOriginal(); ok {
+		metadata.CapturedAt = &capturedAt
+	}
+
+	metadata.CameraMake = stringTag(exifData, exifData.IFD0, exif.TagMake)
+	metadata.CameraModel = stringTag(exifData, exifData.IFD0, exif.TagModel)
+	metadata.Lens = stringTag(exifData, exifData.ExifIFD, exif.TagLensModel)
+
+	if entry, ok := exifData.ExifIFD.Find(exif.TagExposureTime); ok {
+		if exposure, ok := exifData.RationalString(entry); ok {
+			metadata.ExposureTime = &exposure
+		}
+	}
+
+	metadata.FNumber = rationalTag(exifData, exif.TagFNumber)
+	metadata.FocalLength = rationalTag(exifData, exif.TagFocalLength)
+
+	if entry, ok := exifData.ExifIFD.Find(exif.TagISOSpeed); ok {
+		if iso, ok := exifData.Uint(entry); ok {
+			isoValue := int(iso)
+			metadata.ISO = &isoValue
+		}
+	}
+
+	if latitude, longitude, ok := exifData.GPS(); ok {
+		metadata.GPSLatitude = &latitude
+		metadata.GPSLongitude = &longitude
+	}
+
+	return metadata
+}
+
+func stringTag(exifData *exif.Exif, ifd exif.IFD, tag uint16) *string {
+	value, ok := exifData.StringTag(ifd, tag)
+	if !ok {
+		return nil
+	}
+
+	return &value
+}
+
+func rationalTag(exifData *exif.Exif, tag uint16) *float64 {
+	entry, ok := exifData.ExifIFD.Find(tag)
+	if !ok {
+		return nil
+	}
+
+	values := exifData.Rationals(entry)
+	if len(values) == 0 {
+		return nil
+	}
+
+	return &values[0]
+}
@@ -4,118 +4,413 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"runtime"
+	"slices"
+	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
 
 	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/exif"
 	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
 	"test-task-photo-booth/src/entities/dtos"
 )
 
 type PhotoPublishUseCase struct {
-	queue clients.PhotoQueue
-	log   *zerolog.Logger
+	queue       clients.PhotoQueue
+	backgrounds clients.BackgroundStorage
+	templates   clients.TemplateStorage
+	log         *zerolog.Logger
 }
 
-func NewPhotoPublishUseCase(queue clients.PhotoQueue, l *zerolog.Logger) PhotoPublishUseCase {
+func NewPhotoPublishUseCase(
+	queue clients.PhotoQueue,
+	backgrounds clients.BackgroundStorage,
+	templates clients.TemplateStorage,
+	l *zerolog.Logger,
+) PhotoPublishUseCase {
 	return PhotoPublishUseCase{
-		queue: queue,
-		log:   l,
+		queue:       queue,
+		backgrounds: backgrounds,
+		templates:   templates,
+		log:         l,
 	}
 }
 
+// checkReferences makes sure library items chosen in options exist, consumer can't process photo without them
+// and photo queue never redelivers failed message
+func (p PhotoPublishUseCase) checkReferences(options dtos.ProcessingOptions) error {
+	ctx := context.Background()
+
+	if options.ChromaKey != nil {
+		background, err := p.backgrounds.FindOne(ctx, options.ChromaKey.Background)
+		if err != nil {
+			return fmt.Errorf("backgrounds.FindOne(): %w", err)
+		}
+
+		if background.IsDeleted {
+			return customErrors.ErrBackgroundNotFound
+		}
+	}
+
+	if options.Template != "" {
+		template, err := p.templates.FindOne(ctx, options.Template)
+		if err != nil {
+			return fmt.Errorf("templates.FindOne(): %w", err)
+		}
+
+		if template.IsDeleted {
+			return customErrors.ErrTemplateNotFound
+		}
+	}
+
+	return nil
+}
+
 func (p PhotoPublishUseCase) AddInQueue(photo *dtos.Photo) error {
-	if err := p.queue.Publish(photo); err != nil {
+	if err := p.checkReferences(photo.Options); err != nil {
+		return fmt.Errorf("p.checkReferences() failed: %w", err)
+	}
+
+	message := &dtos.PhotoMessage{
+		Type:    entities.PhotoMessageUpload,
+		Data:    photo.Data,
+		Options: photo.Options,
+	}
+
+	if err := p.queue.Publish(message); err != nil {
 		return fmt.Errorf("error adding photo to queue: %v", err)
 	}
 
 	return nil
 }
 
+// AddImportInQueue adds job for consumer to download photo by url
+func (p PhotoPublishUseCase) AddImportInQueue(photoURL string, options dtos.ProcessingOptions) error {
+	if err := p.checkReferences(options); err != nil {
+		return fmt.Errorf("p.checkReferences() failed: %w", err)
+	}
+
+	message := &dtos.PhotoMessage{
+		Type:      entities.PhotoMessageImport,
+		SourceURL: photoURL,
+		Options:   options,
+	}
+
+	if err := p.queue.Publish(message); err != nil {
+		return fmt.Errorf("error adding photo import to queue: %v", err)
+	}
+
+	return nil
+}
+
 const (
 	photoResize75 = 75
 	photoResize50 = 50
 	photoResize25 = 25
 )
 
+const importTimeout = 2 * time.Minute
+
 type PhotoConsumeUseCase struct {
-	db  clients.PhotoStorage
-	log *zerolog.Logger
+	db            clients.PhotoStorage
+	stages        photoStages
+	fetcher       clients.PhotoFetcher
+	uploads       clients.UploadStorage
+	processor     utils.ImageProcessor
+	watermark     *watermark
+	workers       int
+	exifStripMode string
+	profiles      []variantProfile
+	log           *zerolog.Logger
+
+	flagDuplicates     bool
+	duplicateThreshold int
+	placeholders       placeholderOptions
+	quality            qualityThresholds
+	color              colorHandling
+	attribution        attributionSettings
+	strips             stripOptions
+	boomerang          boomerangOptions
 }
 
-func NewPhotoConsumeUseCase(storage clients.PhotoStorage, l *zerolog.Logger) PhotoConsumeUseCase {
+func NewPhotoConsumeUseCase(
+	storage clients.PhotoStorage,
+	backgrounds clients.BackgroundStorage,
+	templates clients.TemplateStorage,
+	fetcher clients.PhotoFetcher,
+	uploads clients.UploadStorage,
+	l *zerolog.Logger,
+) (PhotoConsumeUseCase, error) {
+	viper.SetDefault(configProcessingWorkers, runtime.NumCPU())
+	viper.SetDefault(configExifStripOriginal, exif.StripSensitive)
+	viper.SetDefault(configDuplicatesThreshold, defaultDuplicatesThreshold)
+
+	watermarks, err := loadWatermarks()
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadWatermarks() failed: %w", err)
+	}
+
+	defaultWatermark, err := loadDefaultWatermark(watermarks)
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadDefaultWatermark() failed: %w", err)
+	}
+
+	processor, err := loadImageProcessor()
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadImageProcessor() failed: %w", err)
+	}
+
+	profiles, err := loadVariantProfiles(watermarks, processor)
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadVariantProfiles() failed: %w", err)
+	}
+
+	placeholders, err := loadPlaceholderOptions()
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadPlaceholderOptions() failed: %w", err)
+	}
+
+	stages, err := loadPhotoStages(backgrounds, templates)
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadPhotoStages() failed: %w", err)
+	}
+
+	color, err := loadColorHandling()
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadColorHandling() failed: %w", err)
+	}
+
+	attribution, err := loadAttributionSettings()
+	if err != nil {
+		return PhotoConsumeUseCase{}, fmt.Errorf("loadAttributionSettings() failed: %w", err)
+	}
+
 	return PhotoConsumeUseCase{
-		db:  storage,
-		log: l,
+		db:            storage,
+		stages:        stages,
+		fetcher:       fetcher,
+		uploads:       uploads,
+		processor:     processor,
+		watermark:     defaultWatermark,
+		workers:       max(viper.GetInt(configProcessingWorkers), 1),
+		exifStripMode: viper.GetString(configExifStripOriginal),
+		profiles:      profiles,
+		log:           l,
+
+		flagDuplicates:     viper.GetBool(configDuplicatesFlagOnIngest),
+		duplicateThreshold: viper.GetInt(configDuplicatesThreshold),
+		placeholders:       placeholders,
+		quality:            loadQualityThresholds(),
+		color:              color,
+		attribution:        attribution,
+		strips:             loadStripOptions(),
+		boomerang:          loadBoomerangOptions(),
+	}, nil
+}
+
+// Import downloads photo from remote url and creates it same way as uploaded one
+func (p PhotoConsumeUseCase) Import(photoURL string, options dtos.ProcessingOptions) (dtos.Photo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+	defer cancel()
+
+	data, err := p.fetcher.Fetch(ctx, photoURL)
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("fetcher.Fetch(): %w", err)
+	}
+
+	photo := dtos.Photo{Data: base64.StdEncoding.EncodeToString(data), Options: options}
+	if err := p.Create(&photo); err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.Create(): %w", err)
+	}
+
+	return photo, nil
+}
+
+// CreateFromUpload creates photo from finalized direct upload. Upload is deleted even if photo isn't created,
+// photo queue is auto-ack and message is never redelivered
+func (p PhotoConsumeUseCase) CreateFromUpload(uploadID string, options dtos.ProcessingOptions) (dtos.Photo, error) {
+	ctx := context.Background()
+
+	defer func() {
+		if err := p.uploads.Delete(ctx, uploadID); err != nil {
+			p.log.Warn().Err(err).Msgf("uploads.Delete() failed for upload: %s", uploadID)
+		}
+	}()
+
+	data, err := p.uploads.Load(ctx, uploadID)
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("uploads.Load(): %w", err)
+	}
+
+	photo := dtos.Photo{Data: base64.StdEncoding.EncodeToString(data), Options: options}
+	if err := p.Create(&photo); err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.Create(): %w", err)
 	}
+
+	return photo, nil
 }
 
 func (p PhotoConsumeUseCase) Create(photo *dtos.Photo) error {
 	ctx := context.Background()
+	start := time.Now()
 
-	decodeData, err := base64.StdEncoding.DecodeString(photo.Data)
+	decodeData, mimeType, err := utils.DecodeB64DataURI(photo.Data)
 	if err != nil {
 		return fmt.Errorf("could not decode data: %w", err)
 	}
 
-	extension := utils.GetB64MimeType(decodeData)
+	original, err := prepareOriginal(decodeData, mimeType, p.exifStripMode)
+	if err != nil {
+		return fmt.Errorf("prepareOriginal() failed: %w", err)
+	}
 
-	data75, err := utils.ResizeImageB64(photo.Data, extension, photoResize75)
+	// Data URI and url-safe b64 are normalized, so stored photos are always plain std b64
+	photo.Data = base64.StdEncoding.EncodeToString(original.data)
+
+	// Photo is decoded only once and shared by all variants
+	img, _, err := utils.DecodeImage(original.data)
 	if err != nil {
-		return fmt.Errorf("utils.ResizeImageB64() failed: %w", err)
+		return fmt.Errorf("utils.DecodeImage() failed: %w", err)
 	}
 
-	data50, err := utils.ResizeImageB64(photo.Data, extension, photoResize50)
+	// jpeg.Decode ignores EXIF orientation, variants are stored upright
+	img = utils.ApplyOrientation(img, original.orientation)
+
+	// jpeg.Encode drops ICC profile, so variants are converted to sRGB or get source profile embedded
+	colorProfile := readColorProfile(original.data, mimeType)
+
+	decodeDuration := time.Since(start)
+
+	// Near-duplicates are searched, metadata is extracted and quality is measured by unprocessed photo
+	perceptualHash := utils.DHash(img)
+	metadata := extractMetadata(original.exif, img)
+	metadata.ColorSpace = colorSpaceName(colorProfile, original.exif)
+	qualityScores, qualityFlags := p.quality.assess(img)
+
+	img, iccProfile := p.color.prepare(img, colorProfile)
+
+	photo.Options, err = p.stages.resolveCaption(ctx, photo.Options, metadata.CapturedAt)
 	if err != nil {
-		return fmt.Errorf("utils.ResizeImageB64() failed: %w", err)
+		return fmt.Errorf("p.stages.resolveCaption() failed: %w", err)
 	}
 
-	data25, err := utils.ResizeImageB64(photo.Data, extension, photoResize25)
+	// Chroma key, filters, template and caption apply to placeholders and variants, original is kept as shot
+	img, err = p.stages.apply(ctx, img, photo.Options)
 	if err != nil {
-		return fmt.Errorf("utils.ResizeImageB64() failed: %w", err)
+		return fmt.Errorf("p.stages.apply() failed: %w", err)
 	}
 
 	photoDB := &dtos.PhotoDB{
 		DataOrigin: photo.Data,
-		Data75:     data75,
-		Data50:     data50,
-		Data25:     data25,
 		IsDeleted:  false,
+
+		RemovedExifTags: original.removedExifTags,
+		Metadata:        metadata,
+		PerceptualHash:  &perceptualHash,
+		Options:         photo.Options,
+		QualityScores:   qualityScores,
+		QualityFlags:    qualityFlags,
+	}
+
+	if len(qualityFlags) > 0 {
+		p.log.Warn().Strs("quality_flags", qualityFlags).Msg("low quality photo ingested")
 	}
 
+	if p.flagDuplicates {
+		duplicateOf, err := p.findDuplicate(ctx, perceptualHash)
+		if err != nil {
+			return fmt.Errorf("p.findDuplicate() failed: %w", err)
+		}
+
+		if duplicateOf != "" {
+			p.log.Warn().Str("duplicate_of", duplicateOf).Msg("near-duplicate photo ingested")
+		}
+
+		photoDB.DuplicateOf = duplicateOf
+	}
+
+	if err := generatePlaceholders(img, photoDB, p.placeholders); err != nil {
+		return fmt.Errorf("generatePlaceholders() failed: %w", err)
+	}
+
+	embedded := newVariantMetadata(p.attribution.resolve(photo.Options.Event), iccProfile)
+
+	variantsStart := time.Now()
+	if err := generateVariants(ctx, img, embedded, photoDB, p.processor, p.watermark, p.profiles, p.workers, p.log); err != nil {
+		return fmt.Errorf("generateVariants() failed: %w", err)
+	}
+
+	variantsDuration := time.Since(variantsStart)
+
+	storeStart := time.Now()
 	if err := p.db.Create(ctx, photoDB); err != nil {
 		return fmt.Errorf("db.Create(): %w", err)
 	}
 
+	p.log.Info().
+		Str("id", photoDB.ID).
+		Int("orientation", original.orientation).
+		Strs("removed_exif_tags", original.removedExifTags).
+		Dur("decode_ms", decodeDuration).
+		Dur("variants_ms", variantsDuration).
+		Dur("store_ms", time.Since(storeStart)).
+		Dur("total_ms", time.Since(start)).
+		Msg("photo processed")
+
 	photo.ID = photoDB.ID
 	photo.IsDeleted = photoDB.IsDeleted
+	photo.RemovedExifTags = photoDB.RemovedExifTags
+	photo.PerceptualHash = formatPerceptualHash(photoDB.PerceptualHash)
+	photo.DuplicateOf = photoDB.DuplicateOf
+	photo.BlurHash = photoDB.BlurHash
+	photo.Palette = photoDB.Palette
+	photo.QualityScores = photoDB.QualityScores
+	photo.QualityFlags = photoDB.QualityFlags
 
 	return nil
 }
 
 type PhotoUseCase struct {
-	db  clients.PhotoStorage
-	log *zerolog.Logger
+	db               clients.PhotoStorage
+	similarThreshold int
+	quality          qualityThresholds
+	log              *zerolog.Logger
 }
 
 func NewPhotoUseCase(storage clients.PhotoStorage, l *zerolog.Logger) PhotoUseCase {
+	viper.SetDefault(configDuplicatesThreshold, defaultDuplicatesThreshold)
+
 	return PhotoUseCase{
-		db:  storage,
-		log: l,
+		db:               storage,
+		similarThreshold: viper.GetInt(configDuplicatesThreshold),
+		quality:          loadQualityThresholds(),
+		log:              l,
 	}
 }
 
-func (p PhotoUseCase) GetAllPhotos() ([]dtos.Photo, error) {
+func (p PhotoUseCase) GetAllPhotos(filter dtos.PhotoFilter) ([]dtos.Photo, error) {
 	ctx := context.Background()
 	photosList := make([]dtos.Photo, 0)
 
-	photoListDB, err := p.db.FindAll(ctx)
+	photoListDB, err := p.db.FindAll(ctx, filter)
 	if err != nil {
 		return photosList, fmt.Errorf("db.FindAll(): %w", err)
 	}
 
 	for _, photoDB := range photoListDB {
-		photosList = append(photosList, dtos.Photo{ID: photoDB.ID, IsDeleted: photoDB.IsDeleted})
+		photosList = append(photosList, dtos.Photo{
+			ID:        photoDB.ID,
+			IsDeleted: photoDB.IsDeleted,
+			BlurHash:  photoDB.BlurHash,
+			Palette:   photoDB.Palette,
+
+			QualityScores: photoDB.QualityScores,
+			QualityFlags:  photoDB.QualityFlags,
+		})
 	}
 
 	return photosList, nil
@@ -133,8 +428,96 @@ func (p PhotoUseCase) GetByID(id, quality string) (dtos.Photo, error) {
 	return photo, nil
 }
 
+func (p PhotoUseCase) GetMetadata(id string) (dtos.PhotoMetadata, error) {
+	ctx := context.Background()
+	metadata, err := p.db.FindMetadata(ctx, id)
+	if err != nil {
+		return dtos.PhotoMetadata{}, fmt.Errorf("db.FindMetadata(): %w", err)
+	}
+
+	return metadata, nil
+}
+
+// GetVariant returns photo rendition generated by variant profile in format, JPEG when format is empty
+func (p PhotoUseCase) GetVariant(id, name, format string) (dtos.PhotoVariant, error) {
+	if format == "" {
+		format = entities.VariantFormatJPEG
+	}
+
+	mimeType, ok := variantMimeTypes[format]
+	if !ok {
+		return dtos.PhotoVariant{}, fmt.Errorf("%w: %s", customErrors.ErrUnsupportedVariantFormat, format)
+	}
+
+	ctx := context.Background()
+	variant, err := p.db.FindVariant(ctx, id, name, mimeType)
+	if err != nil {
+		return dtos.PhotoVariant{}, fmt.Errorf("db.FindVariant(): %w", err)
+	}
+
+	return variant, nil
+}
+
+// GetVariantImage returns decoded photo rendition in smallest of accepted mime types profile was rendered in.
+// Lossless WebP is mostly bigger than JPEG of photo, so it's served only when it's smaller.
+// Every profile has JPEG record, it's served when none of accepted types is stored
+func (p PhotoUseCase) GetVariantImage(id, name string, accepted []string) (dtos.DerivedImage, error) {
+	ctx := context.Background()
+	variants, err := p.db.FindVariants(ctx, id)
+	if err != nil {
+		return dtos.DerivedImage{}, fmt.Errorf("db.FindVariants(): %w", err)
+	}
+
+	mimeType := utils.MimeTypeJPEG
+	smallest := -1
+
+	for _, variant := range variants {
+		if variant.Name != name || !slices.Contains(accepted, variant.MimeType) {
+			continue
+		}
+
+		if smallest < 0 || variant.Size < smallest {
+			mimeType, smallest = variant.MimeType, variant.Size
+		}
+	}
+
+	variant, err := p.db.FindVariant(ctx, id, name, mimeType)
+	if err != nil {
+		return dtos.DerivedImage{}, fmt.Errorf("db.FindVariant(): %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(variant.Data)
+	if err != nil {
+		return dtos.DerivedImage{}, fmt.Errorf("base64.DecodeString() failed: %w", err)
+	}
+
+	return dtos.DerivedImage{MimeType: variant.MimeType, Data: data}, nil
+}
+
+// GetVariants lists photo variants without data
+func (p PhotoUseCase) GetVariants(id string) ([]dtos.PhotoVariant, error) {
+	ctx := context.Background()
+	variants, err := p.db.FindVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindVariants(): %w", err)
+	}
+
+	return variants, nil
+}
+
 func (p PhotoUseCase) getPhotoWithQuality(photoDB dtos.PhotoDB, quality string) dtos.Photo {
-	photo := dtos.Photo{ID: photoDB.ID, IsDeleted: photoDB.IsDeleted}
+	photo := dtos.Photo{
+		ID:              photoDB.ID,
+		IsDeleted:       photoDB.IsDeleted,
+		RemovedExifTags: photoDB.RemovedExifTags,
+		PerceptualHash:  formatPerceptualHash(photoDB.PerceptualHash),
+		DuplicateOf:     photoDB.DuplicateOf,
+		BlurHash:        photoDB.BlurHash,
+		Palette:         photoDB.Palette,
+		Options:         photoDB.Options,
+		QualityScores:   photoDB.QualityScores,
+		QualityFlags:    photoDB.QualityFlags,
+	}
 
 	switch quality {
 	case "100":
@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+
+	"test-task-photo-booth/pkg/icc"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configProcessingWorkers   = "processing.workers"
+	configProcessingResampler = "processing.resampler"
+)
+
+// loadImageProcessor returns processor of resampler photos are resized with unless profile sets own one
+func loadImageProcessor() (utils.ImageProcessor, error) {
+	viper.SetDefault(configProcessingResampler, utils.ResamplerLanczos3)
+
+	processor, err := utils.NewImageProcessor(viper.GetString(configProcessingResampler))
+	if err != nil {
+		return nil, fmt.Errorf("utils.NewImageProcessor() failed: %w", err)
+	}
+
+	return processor, nil
+}
+
+type photoVariant struct {
+	percentage uint
+	setData    func(photoDB *dtos.PhotoDB, data string)
+}
+
+var photoVariants = []photoVariant{
+	{percentage: photoResize75, setData: func(photoDB *dtos.PhotoDB, data string) { photoDB.Data75 = data }},
+	{percentage: photoResize50, setData: func(photoDB *dtos.PhotoDB, data string) { photoDB.Data50 = data }},
+	{percentage: photoResize25, setData: func(photoDB *dtos.PhotoDB, data string) { photoDB.Data25 = data }},
+}
+
+// generateVariants resizes once decoded image to every variant and profile concurrently, at most workers at once.
+// Percentage variants are resized with processor and get default watermark, profiles are rendered with their own ones.
+// Metadata is embedded into every variant
+func generateVariants(
+	ctx context.Context,
+	img image.Image,
+	embedded variantMetadata,
+	photoDB *dtos.PhotoDB,
+	processor utils.ImageProcessor,
+	defaultWatermark *watermark,
+	profiles []variantProfile,
+	workers int,
+	log *zerolog.Logger,
+) error {
+	results := make([]string, len(photoVariants))
+	profileResults := make([][]dtos.PhotoVariant, len(profiles))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+
+	for i, variant := range photoVariants {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			resizeStart := time.Now()
+			resized := utils.ResizeImage(processor, img, variant.percentage)
+			resized = applyWatermark(resized, defaultWatermark, photoDB.Options)
+			resizeDuration := time.Since(resizeStart)
+
+			encodeStart := time.Now()
+			data, err := utils.EncodeJPEG(resized, utils.VariantJPEGQuality)
+			if err != nil {
+				return fmt.Errorf("utils.EncodeJPEG() failed for variant %d: %w", variant.percentage, err)
+			}
+
+			data, err = embedded.embed(data)
+			if err != nil {
+				return fmt.Errorf("embedded.embed() failed for variant %d: %w", variant.percentage, err)
+			}
+
+			log.Debug().
+				Uint("variant", variant.percentage).
+				Dur("resize_ms", resizeDuration).
+				Dur("encode_ms", time.Since(encodeStart)).
+				Msg("photo variant generated")
+
+			results[i] = base64.StdEncoding.EncodeToString(data)
+
+			return nil
+		})
+	}
+
+	for i, profile := range profiles {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			renderStart := time.Now()
+
+			variants, err := renderProfile(img, embedded, profile, photoDB.Options, log)
+			if err != nil {
+				return fmt.Errorf("renderProfile() failed for profile %s: %w", profile.Name, err)
+			}
+
+			log.Debug().
+				Str("profile", profile.Name).
+				Strs("formats", profile.Formats).
+				Dur("render_ms", time.Since(renderStart)).
+				Msg("photo variant generated")
+
+			profileResults[i] = variants
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("variants generation failed: %w", err)
+	}
+
+	for i, variant := range photoVariants {
+		variant.setData(photoDB, results[i])
+	}
+
+	photoDB.Variants = make([]dtos.PhotoVariant, 0, len(profiles))
+	for _, variants := range profileResults {
+		photoDB.Variants = append(photoDB.Variants, variants...)
+	}
+
+	return nil
+}
+
+// decodeStoredOriginal decodes stored original photo and turns it upright, pixels are left in color space
+// of returned profile, which is nil when original has no ICC profile
+func decodeStoredOriginal(photoDB dtos.PhotoDB) (image.Image, *icc.Profile, error) {
+	original, err := base64.StdEncoding.DecodeString(photoDB.DataOrigin)
+	if err != nil {
+		return nil, nil, fmt.Errorf("base64.DecodeString() failed: %w", err)
+	}
+
+	img, mimeType, err := utils.DecodeImage(original)
+	if err != nil {
+		return nil, nil, fmt.Errorf("utils.DecodeImage() failed: %w", err)
+	}
+
+	return utils.ApplyOrientation(img, readOrientation(original, mimeType)), readColorProfile(original, mimeType), nil
+}
@@ -0,0 +1,63 @@
+package usecases
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configPlaceholdersBlurHashX   = "placeholders.blurhashX"
+	configPlaceholdersBlurHashY   = "placeholders.blurhashY"
+	configPlaceholdersPaletteSize = "placeholders.paletteSize"
+)
+
+const (
+	defaultBlurHashX   = 4
+	defaultBlurHashY   = 3
+	defaultPaletteSize = 5
+)
+
+type placeholderOptions struct {
+	blurHashX   int
+	blurHashY   int
+	paletteSize int
+}
+
+func loadPlaceholderOptions() (placeholderOptions, error) {
+	viper.SetDefault(configPlaceholdersBlurHashX, defaultBlurHashX)
+	viper.SetDefault(configPlaceholdersBlurHashY, defaultBlurHashY)
+	viper.SetDefault(configPlaceholdersPaletteSize, defaultPaletteSize)
+
+	options := placeholderOptions{
+		blurHashX:   viper.GetInt(configPlaceholdersBlurHashX),
+		blurHashY:   viper.GetInt(configPlaceholdersBlurHashY),
+		paletteSize: max(viper.GetInt(configPlaceholdersPaletteSize), 0),
+	}
+
+	for _, components := range []int{options.blurHashX, options.blurHashY} {
+		if components < 1 || components > utils.MaxBlurHashComponents {
+			return options, fmt.Errorf("blurhash components must be 1-%d", utils.MaxBlurHashComponents)
+		}
+	}
+
+	return options, nil
+}
+
+// generatePlaceholders computes BlurHash and dominant colors, so clients can render tile before photo is loaded
+func generatePlaceholders(img image.Image, photoDB *dtos.PhotoDB, options placeholderOptions) error {
+	blurHash, err := utils.BlurHash(img, options.blurHashX, options.blurHashY)
+	if err != nil {
+		return fmt.Errorf("utils.BlurHash() failed: %w", err)
+	}
+
+	photoDB.BlurHash = blurHash
+	photoDB.Palette = utils.DominantColors(img, options.paletteSize)
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configQualityMinSharpness = "quality.minSharpness"
+	configQualityMinLuminance = "quality.minLuminance"
+	configQualityMaxLuminance = "quality.maxLuminance"
+	configQualityMaxClipping  = "quality.maxClipping"
+	configQualityMinContrast  = "quality.minContrast"
+)
+
+const (
+	defaultQualityMinSharpness = 100
+	defaultQualityMinLuminance = 0.1
+	defaultQualityMaxLuminance = 0.9
+	defaultQualityMaxClipping  = 0.4
+	defaultQualityMinContrast  = 0.03
+
+	minBurstPhotos = 2
+	maxBurstPhotos = 50
+)
+
+// qualityThresholds flag photo when its score is out of range
+type qualityThresholds struct {
+	minSharpness float64
+	minLuminance float64
+	maxLuminance float64
+	maxClipping  float64
+	minContrast  float64
+}
+
+func loadQualityThresholds() qualityThresholds {
+	viper.SetDefault(configQualityMinSharpness, defaultQualityMinSharpness)
+	viper.SetDefault(configQualityMinLuminance, defaultQualityMinLuminance)
+	viper.SetDefault(configQualityMaxLuminance, defaultQualityMaxLuminance)
+	viper.SetDefault(configQualityMaxClipping, defaultQualityMaxClipping)
+	viper.SetDefault(configQualityMinContrast, defaultQualityMinContrast)
+
+	return qualityThresholds{
+		minSharpness: viper.GetFloat64(configQualityMinSharpness),
+		minLuminance: viper.GetFloat64(configQualityMinLuminance),
+		maxLuminance: viper.GetFloat64(configQualityMaxLuminance),
+		maxClipping:  viper.GetFloat64(configQualityMaxClipping),
+		minContrast:  viper.GetFloat64(configQualityMinContrast),
+	}
+}
+
+// assess measures unprocessed photo and flags scores below thresholds
+func (t qualityThresholds) assess(img image.Image) (*dtos.QualityScores, []string) {
+	quality := utils.MeasureQuality(img)
+
+	scores := &dtos.QualityScores{
+		Sharpness:         quality.Sharpness,
+		Luminance:         quality.Luminance,
+		ShadowClipping:    quality.ShadowClipping,
+		HighlightClipping: quality.HighlightClipping,
+		Contrast:          quality.Contrast,
+	}
+
+	return scores, t.flags(*scores)
+}
+
+func (t qualityThresholds) flags(scores dtos.QualityScores) []string {
+	flags := make([]string, 0)
+
+	if scores.Sharpness < t.minSharpness {
+		flags = append(flags, entities.QualityFlagBlurry)
+	}
+
+	if scores.Luminance < t.minLuminance || scores.ShadowClipping > t.maxClipping {
+		flags = append(flags, entities.QualityFlagUnderexposed)
+	}
+
+	if scores.Luminance > t.maxLuminance || scores.HighlightClipping > t.maxClipping {
+		flags = append(flags, entities.QualityFlagOverexposed)
+	}
+
+	// Covered lens or blank wall gives almost single-colour frame
+	if scores.Contrast < t.minContrast {
+		flags = append(flags, entities.QualityFlagEmpty)
+	}
+
+	return flags
+}
+
+// GetBestOfBurst ranks photos by sharpness, photos without quality flags first.
+// Photos stored before quality checks are measured from their originals
+func (p PhotoUseCase) GetBestOfBurst(ids []string) (dtos.BestOfBurst, error) {
+	ctx := context.Background()
+
+	ids = uniqueIDs(ids)
+	if len(ids) < minBurstPhotos || len(ids) > maxBurstPhotos {
+		return dtos.BestOfBurst{}, fmt.Errorf("%w: burst takes from %d to %d photos, got %d",
+			customErrors.ErrInvalidBurst, minBurstPhotos, maxBurstPhotos, len(ids))
+	}
+
+	photos, err := p.db.FindQualityScores(ctx, ids)
+	if err != nil {
+		return dtos.BestOfBurst{}, fmt.Errorf("db.FindQualityScores(): %w", err)
+	}
+
+	if len(photos) != len(ids) {
+		return dtos.BestOfBurst{}, fmt.Errorf("%w: %d of %d photos not found", customErrors.ErrInvalidBurst, len(ids)-len(photos), len(ids))
+	}
+
+	for i := range photos {
+		if photos[i].QualityScores != nil {
+			continue
+		}
+
+		photoDB, err := p.db.FindOne(ctx, photos[i].ID)
+		if err != nil {
+			return dtos.BestOfBurst{}, fmt.Errorf("db.FindOne(): %w", err)
+		}
+
+		img, _, err := decodeStoredOriginal(photoDB)
+		if err != nil {
+			return dtos.BestOfBurst{}, fmt.Errorf("decodeStoredOriginal() failed: %w", err)
+		}
+
+		photos[i].QualityScores, photos[i].QualityFlags = p.quality.assess(img)
+	}
+
+	sort.SliceStable(photos, func(i, j int) bool {
+		iFlagged, jFlagged := len(photos[i].QualityFlags) > 0, len(photos[j].QualityFlags) > 0
+		if iFlagged != jFlagged {
+			return !iFlagged
+		}
+
+		return photos[i].QualityScores.Sharpness > photos[j].QualityScores.Sharpness
+	})
+
+	return dtos.BestOfBurst{ID: photos[0].ID, Photos: photos}, nil
+}
@@ -0,0 +1,253 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configReprocessWorkers = "reprocess.workers"
+)
+
+// defaultReprocessWorkers keeps regeneration to one variant at a time, so cpu is left for live uploads
+const defaultReprocessWorkers = 1
+
+// ReprocessUseCase starts regeneration of stored photos, every photo is separate job of reprocess queue
+type ReprocessUseCase struct {
+	photos clients.PhotoStorage
+	jobs   clients.ReprocessJobStorage
+	queue  clients.PhotoQueue
+	log    *zerolog.Logger
+}
+
+func NewReprocessUseCase(
+	photos clients.PhotoStorage,
+	jobs clients.ReprocessJobStorage,
+	queue clients.PhotoQueue,
+	l *zerolog.Logger,
+) ReprocessUseCase {
+	return ReprocessUseCase{
+		photos: photos,
+		jobs:   jobs,
+		queue:  queue,
+		log:    l,
+	}
+}
+
+// Start selects photos, creates job counting them and enqueues every photo.
+// Photos which couldn't be enqueued are counted as failed, so job still completes
+func (r ReprocessUseCase) Start(reprocess dtos.Reprocess) (dtos.ReprocessJob, error) {
+	ctx := context.Background()
+
+	ids, err := r.selectPhotos(ctx, reprocess)
+	if err != nil {
+		return dtos.ReprocessJob{}, fmt.Errorf("r.selectPhotos() failed: %w", err)
+	}
+
+	job := dtos.ReprocessJob{Total: len(ids)}
+	if err := r.jobs.Create(ctx, &job); err != nil {
+		return dtos.ReprocessJob{}, fmt.Errorf("jobs.Create(): %w", err)
+	}
+
+	for i, id := range ids {
+		message := &dtos.PhotoMessage{
+			Type:    entities.PhotoMessageReprocess,
+			PhotoID: id,
+			JobID:   job.ID,
+		}
+
+		if err := r.queue.Publish(message); err != nil {
+			if err := r.jobs.AddProgress(ctx, job.ID, 0, len(ids)-i); err != nil {
+				r.log.Error().Err(err).Str("job_id", job.ID).Msg("failed to count not enqueued photos")
+			}
+
+			return dtos.ReprocessJob{}, fmt.Errorf("error adding photo reprocess to queue: %v", err)
+		}
+	}
+
+	r.log.Info().Str("job_id", job.ID).Int("total", job.Total).Msg("photos reprocess started")
+
+	return withReprocessStatus(job), nil
+}
+
+// GetJob returns job progress
+func (r ReprocessUseCase) GetJob(id string) (dtos.ReprocessJob, error) {
+	ctx := context.Background()
+
+	job, err := r.jobs.FindOne(ctx, id)
+	if err != nil {
+		return dtos.ReprocessJob{}, fmt.Errorf("jobs.FindOne(): %w", err)
+	}
+
+	return withReprocessStatus(job), nil
+}
+
+// selectPhotos resolves ids of photos to reprocess, exactly one of ids, filter and all has to be set
+func (r ReprocessUseCase) selectPhotos(ctx context.Context, reprocess dtos.Reprocess) ([]string, error) {
+	selections := 0
+	for _, selected := range []bool{len(reprocess.PhotoIDs) > 0, reprocess.Filter != nil, reprocess.All} {
+		if selected {
+			selections++
+		}
+	}
+
+	if selections != 1 {
+		return nil, fmt.Errorf("%w: set exactly one of photo ids, filter or all", customErrors.ErrInvalidReprocess)
+	}
+
+	if len(reprocess.PhotoIDs) > 0 {
+		return uniqueIDs(reprocess.PhotoIDs), nil
+	}
+
+	filter := dtos.PhotoFilter{}
+	if reprocess.Filter != nil {
+		filter = *reprocess.Filter
+	}
+
+	ids, err := r.photos.FindIDs(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindIDs(): %w", err)
+	}
+
+	return ids, nil
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
+
+func withReprocessStatus(job dtos.ReprocessJob) dtos.ReprocessJob {
+	job.Status = entities.ReprocessStatusRunning
+	if job.Processed+job.Failed >= job.Total {
+		job.Status = entities.ReprocessStatusDone
+	}
+
+	return job
+}
+
+// ReprocessConsumeUseCase regenerates stored photos one by one and counts job progress
+type ReprocessConsumeUseCase struct {
+	photos PhotoConsumeUseCase
+	jobs   clients.ReprocessJobStorage
+	log    *zerolog.Logger
+}
+
+func NewReprocessConsumeUseCase(
+	storage clients.PhotoStorage,
+	backgrounds clients.BackgroundStorage,
+	templates clients.TemplateStorage,
+	jobs clients.ReprocessJobStorage,
+	l *zerolog.Logger,
+) (ReprocessConsumeUseCase, error) {
+	viper.SetDefault(configReprocessWorkers, defaultReprocessWorkers)
+
+	photos, err := NewPhotoConsumeUseCase(storage, backgrounds, templates, nil, nil, l)
+	if err != nil {
+		return ReprocessConsumeUseCase{}, fmt.Errorf("NewPhotoConsumeUseCase() failed: %w", err)
+	}
+
+	photos.workers = max(viper.GetInt(configReprocessWorkers), 1)
+
+	return ReprocessConsumeUseCase{
+		photos: photos,
+		jobs:   jobs,
+		log:    l,
+	}, nil
+}
+
+// Reprocess regenerates photo and counts it in job as processed or failed
+func (r ReprocessConsumeUseCase) Reprocess(jobID, photoID string) error {
+	ctx := context.Background()
+
+	regenerateErr := r.photos.Regenerate(ctx, photoID)
+
+	processed, failed := 1, 0
+	if regenerateErr != nil {
+		processed, failed = 0, 1
+	}
+
+	if jobID != "" {
+		if err := r.jobs.AddProgress(ctx, jobID, processed, failed); err != nil {
+			r.log.Error().Err(err).Str("job_id", jobID).Msg("failed to count reprocess progress")
+		}
+	}
+
+	if regenerateErr != nil {
+		return fmt.Errorf("r.photos.Regenerate() failed: %w", regenerateErr)
+	}
+
+	return nil
+}
+
+// Regenerate rebuilds quality scores, placeholders and variants of stored photo from its original with current config.
+// Resolved caption is kept in photo options, so photo gets the same caption it was printed with at ingest
+func (p PhotoConsumeUseCase) Regenerate(ctx context.Context, id string) error {
+	start := time.Now()
+
+	photoDB, err := p.db.FindOne(ctx, id)
+	if err != nil {
+		return fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	if photoDB.IsDeleted {
+		return customErrors.ErrPhotoDeleted
+	}
+
+	img, colorProfile, err := decodeStoredOriginal(photoDB)
+	if err != nil {
+		return fmt.Errorf("decodeStoredOriginal() failed: %w", err)
+	}
+
+	// Photos stored before quality checks get their scores
+	photoDB.QualityScores, photoDB.QualityFlags = p.quality.assess(img)
+
+	img, iccProfile := p.color.prepare(img, colorProfile)
+
+	img, err = p.stages.apply(ctx, img, photoDB.Options)
+	if err != nil {
+		return fmt.Errorf("p.stages.apply() failed: %w", err)
+	}
+
+	if err := generatePlaceholders(img, &photoDB, p.placeholders); err != nil {
+		return fmt.Errorf("generatePlaceholders() failed: %w", err)
+	}
+
+	// Attribution is resolved from current config, so changed settings reach existing photos
+	embedded := newVariantMetadata(p.attribution.resolve(photoDB.Options.Event), iccProfile)
+
+	if err := generateVariants(ctx, img, embedded, &photoDB, p.processor, p.watermark, p.profiles, p.workers, p.log); err != nil {
+		return fmt.Errorf("generateVariants() failed: %w", err)
+	}
+
+	if err := p.db.Update(ctx, photoDB); err != nil {
+		return fmt.Errorf("db.Update(): %w", err)
+	}
+
+	p.log.Info().
+		Str("id", photoDB.ID).
+		Int("variants", len(photoDB.Variants)).
+		Dur("total_ms", time.Since(start)).
+		Msg("photo reprocessed")
+
+	return nil
+}
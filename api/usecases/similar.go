@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configDuplicatesFlagOnIngest = "duplicates.flagOnIngest"
+	configDuplicatesThreshold    = "duplicates.threshold"
+)
+
+const (
+	defaultDuplicatesThreshold = 5
+	similarPhotosLimit         = 100
+)
+
+// GetSimilar returns photos with perceptual hash within threshold Hamming distance, default threshold is used when nil
+func (p PhotoUseCase) GetSimilar(id string, threshold *int) ([]dtos.SimilarPhoto, error) {
+	ctx := context.Background()
+
+	distance := p.similarThreshold
+	if threshold != nil {
+		distance = *threshold
+	}
+
+	photoDB, err := p.db.FindOne(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	if photoDB.PerceptualHash == nil {
+		return nil, customErrors.ErrNoPerceptualHash
+	}
+
+	// photo itself is always found with distance 0
+	found, err := p.db.FindSimilar(ctx, *photoDB.PerceptualHash, distance, similarPhotosLimit+1)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindSimilar(): %w", err)
+	}
+
+	similar := make([]dtos.SimilarPhoto, 0, len(found))
+
+	for _, photo := range found {
+		if photo.ID != id && len(similar) < similarPhotosLimit {
+			similar = append(similar, photo)
+		}
+	}
+
+	return similar, nil
+}
+
+// findDuplicate returns id of already stored near-duplicate of photo or empty string
+func (p PhotoConsumeUseCase) findDuplicate(ctx context.Context, hash uint64) (string, error) {
+	found, err := p.db.FindSimilar(ctx, hash, p.duplicateThreshold, 1)
+	if err != nil {
+		return "", fmt.Errorf("db.FindSimilar(): %w", err)
+	}
+
+	if len(found) == 0 {
+		return "", nil
+	}
+
+	return found[0].ID, nil
+}
+
+func formatPerceptualHash(hash *uint64) string {
+	if hash == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%016x", *hash)
+}
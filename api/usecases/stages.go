@@ -0,0 +1,287 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configChromaKeyColor     = "chromaKey.keyColor"
+	configChromaKeyTolerance = "chromaKey.tolerance"
+	configChromaKeySoftness  = "chromaKey.softness"
+	configEvents             = "events"
+)
+
+const (
+	defaultChromaKeyColor     = "#00B140" // chroma key green
+	defaultChromaKeyTolerance = 0.2
+	defaultChromaKeySoftness  = 0.1
+)
+
+// eventProfile is booth event settings applied to photos uploaded with its name
+type eventProfile struct {
+	ChromaKey   *dtos.ChromaKeyOptions `mapstructure:"chromaKey"`
+	Template    string                 `mapstructure:"template"`    // template id
+	Attribution dtos.Attribution       `mapstructure:"attribution"` // overrides attribution config field by field
+}
+
+// photoStages render processing options onto upright photo,
+// so variants, derived images and compositions of stored photos look alike
+type photoStages struct {
+	backgrounds clients.BackgroundStorage
+	templates   clients.TemplateStorage
+	chromaKey   utils.ChromaKeyOptions
+	captions    captionDefaults
+	events      map[string]eventProfile
+}
+
+func loadPhotoStages(backgrounds clients.BackgroundStorage, templates clients.TemplateStorage) (photoStages, error) {
+	viper.SetDefault(configChromaKeyColor, defaultChromaKeyColor)
+	viper.SetDefault(configChromaKeyTolerance, defaultChromaKeyTolerance)
+	viper.SetDefault(configChromaKeySoftness, defaultChromaKeySoftness)
+
+	key, err := utils.ParseHexColor(viper.GetString(configChromaKeyColor))
+	if err != nil {
+		return photoStages{}, fmt.Errorf("invalid %s: %w", configChromaKeyColor, err)
+	}
+
+	captions, err := loadCaptionDefaults()
+	if err != nil {
+		return photoStages{}, fmt.Errorf("loadCaptionDefaults() failed: %w", err)
+	}
+
+	// viper lowercases map keys, so event names are matched case-insensitively
+	events := make(map[string]eventProfile)
+	if err := viper.UnmarshalKey(configEvents, &events); err != nil {
+		return photoStages{}, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	return photoStages{
+		backgrounds: backgrounds,
+		templates:   templates,
+		chromaKey: utils.ChromaKeyOptions{
+			Key:       key,
+			Tolerance: viper.GetFloat64(configChromaKeyTolerance),
+			Softness:  viper.GetFloat64(configChromaKeySoftness),
+		},
+		captions: captions,
+		events:   events,
+	}, nil
+}
+
+// apply runs chroma key, filters, template and caption, filters don't tint template frame and caption
+func (s photoStages) apply(ctx context.Context, img image.Image, options dtos.ProcessingOptions) (image.Image, error) {
+	img, err := s.applyChromaKey(ctx, img, options)
+	if err != nil {
+		return nil, fmt.Errorf("s.applyChromaKey() failed: %w", err)
+	}
+
+	photoFilters, err := filters.ParseAll(options.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("filters.ParseAll() failed: %w", err)
+	}
+
+	img = filters.Apply(img, photoFilters)
+
+	template, err := s.template(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("s.template() failed: %w", err)
+	}
+
+	if template != nil {
+		img, err = applyTemplate(img, *template)
+		if err != nil {
+			return nil, fmt.Errorf("applyTemplate() failed: %w", err)
+		}
+	}
+
+	caption := options.Caption
+	if caption == nil && template != nil {
+		caption = template.Caption
+	}
+
+	if caption != nil && caption.Text != "" {
+		img, err = s.captions.apply(img, *caption, options.Event, template, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("s.captions.apply() failed: %w", err)
+		}
+	}
+
+	return img, nil
+}
+
+// cacheKey identifies library items and event settings photo is rendered with,
+// so derived images are rendered again after template update or events config change
+func (s photoStages) cacheKey(ctx context.Context, options dtos.ProcessingOptions) (string, error) {
+	key := ""
+
+	chromaKey := options.ChromaKey
+	if chromaKey == nil {
+		chromaKey = s.event(options.Event).ChromaKey
+	}
+
+	// Backgrounds are never changed, only removed from library
+	if chromaKey != nil && chromaKey.Background != "" {
+		key += fmt.Sprintf("&chromaKey=%s,%s", chromaKey.Background, chromaKey.KeyColor)
+
+		if chromaKey.Tolerance != nil {
+			key += fmt.Sprintf(",tolerance=%g", *chromaKey.Tolerance)
+		}
+
+		if chromaKey.Softness != nil {
+			key += fmt.Sprintf(",softness=%g", *chromaKey.Softness)
+		}
+	}
+
+	templateID := options.Template
+	if templateID == "" {
+		templateID = s.event(options.Event).Template
+	}
+
+	if templateID != "" {
+		updatedAt, err := s.templates.FindVersion(ctx, templateID)
+		if err != nil {
+			return "", fmt.Errorf("templates.FindVersion(): %w", err)
+		}
+
+		key += fmt.Sprintf("&template=%s,%d", templateID, updatedAt.UnixNano())
+	}
+
+	return key, nil
+}
+
+// resolveCaption sets caption of upload or template with placeholders substituted,
+// so photo keeps printed text when template changes
+func (s photoStages) resolveCaption(ctx context.Context, options dtos.ProcessingOptions, capturedAt *time.Time) (dtos.ProcessingOptions, error) {
+	caption := options.Caption
+	if caption == nil {
+		template, err := s.template(ctx, options)
+		if err != nil {
+			return options, fmt.Errorf("s.template() failed: %w", err)
+		}
+
+		if template != nil {
+			caption = template.Caption
+		}
+	}
+
+	if caption == nil {
+		return options, nil
+	}
+
+	date := time.Now()
+	if capturedAt != nil {
+		date = *capturedAt
+	}
+
+	resolved := *caption
+	resolved.Text = s.captions.text(caption.Text, options.Event, date)
+	options.Caption = &resolved
+
+	return options, nil
+}
+
+// applyChromaKey replaces backdrop by background chosen on upload or by event, photo is returned as is otherwise
+func (s photoStages) applyChromaKey(ctx context.Context, img image.Image, options dtos.ProcessingOptions) (image.Image, error) {
+	chromaKey := options.ChromaKey
+	if chromaKey == nil {
+		chromaKey = s.event(options.Event).ChromaKey
+	}
+
+	if chromaKey == nil || chromaKey.Background == "" {
+		return img, nil
+	}
+
+	keyOptions := s.chromaKey
+
+	if chromaKey.KeyColor != "" {
+		key, err := utils.ParseHexColor(chromaKey.KeyColor)
+		if err != nil {
+			return nil, fmt.Errorf("utils.ParseHexColor() failed: %w", err)
+		}
+
+		keyOptions.Key = key
+	}
+
+	if chromaKey.Tolerance != nil {
+		keyOptions.Tolerance = *chromaKey.Tolerance
+	}
+
+	if chromaKey.Softness != nil {
+		keyOptions.Softness = *chromaKey.Softness
+	}
+
+	background, err := s.backgrounds.FindOne(ctx, chromaKey.Background)
+	if err != nil {
+		return nil, fmt.Errorf("backgrounds.FindOne(): %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(background.Data)
+	if err != nil {
+		return nil, fmt.Errorf("base64.DecodeString() failed: %w", err)
+	}
+
+	backgroundImg, _, err := utils.DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeImage() failed: %w", err)
+	}
+
+	return utils.ChromaKey(img, backgroundImg, keyOptions), nil
+}
+
+// template returns template chosen on upload or by event, nil for none
+func (s photoStages) template(ctx context.Context, options dtos.ProcessingOptions) (*dtos.Template, error) {
+	templateID := options.Template
+	if templateID == "" {
+		templateID = s.event(options.Event).Template
+	}
+
+	if templateID == "" {
+		return nil, nil
+	}
+
+	template, err := s.templates.FindOne(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("templates.FindOne(): %w", err)
+	}
+
+	return &template, nil
+}
+
+// applyTemplate frames photo into template photo region
+func applyTemplate(img image.Image, template dtos.Template) (image.Image, error) {
+	data, err := base64.StdEncoding.DecodeString(template.Data)
+	if err != nil {
+		return nil, fmt.Errorf("base64.DecodeString() failed: %w", err)
+	}
+
+	frame, _, err := utils.DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeImage() failed: %w", err)
+	}
+
+	background, _ := utils.ParseHexColor(defaultProfileBackground)
+	region := templateRegionRect(templateRegion(template, templatePhotoRegion))
+
+	return utils.Frame(img, frame, region, background), nil
+}
+
+func (s photoStages) event(name string) eventProfile {
+	if name == "" {
+		return eventProfile{}
+	}
+
+	return s.events[strings.ToLower(name)]
+}
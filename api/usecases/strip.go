@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configStripsCellWidth   = "strips.cellWidth"
+	configStripsCellHeight  = "strips.cellHeight"
+	configStripsMaxCellSize = "strips.maxCellSize"
+	configStripsMaxPhotos   = "strips.maxPhotos"
+	configStripsMaxSpacing  = "strips.maxSpacing"
+)
+
+const (
+	defaultStripCellWidth   = 600
+	defaultStripCellHeight  = 400
+	defaultStripMaxCellSize = 2000
+	defaultStripMaxPhotos   = 12
+	defaultStripMaxSpacing  = 200
+
+	minStripPhotos = 2
+	stripQuality   = 95 // strip is re-encoded once more by variants, so it is kept near lossless
+)
+
+type stripOptions struct {
+	cellWidth   int
+	cellHeight  int
+	maxCellSize int
+	maxPhotos   int
+	maxSpacing  int
+}
+
+func loadStripOptions() stripOptions {
+	viper.SetDefault(configStripsCellWidth, defaultStripCellWidth)
+	viper.SetDefault(configStripsCellHeight, defaultStripCellHeight)
+	viper.SetDefault(configStripsMaxCellSize, defaultStripMaxCellSize)
+	viper.SetDefault(configStripsMaxPhotos, defaultStripMaxPhotos)
+	viper.SetDefault(configStripsMaxSpacing, defaultStripMaxSpacing)
+
+	return stripOptions{
+		cellWidth:   viper.GetInt(configStripsCellWidth),
+		cellHeight:  viper.GetInt(configStripsCellHeight),
+		maxCellSize: viper.GetInt(configStripsMaxCellSize),
+		maxPhotos:   viper.GetInt(configStripsMaxPhotos),
+		maxSpacing:  viper.GetInt(configStripsMaxSpacing),
+	}
+}
+
+// AddStripInQueue validates strip and adds job for consumer to compose it from stored photos
+func (p PhotoPublishUseCase) AddStripInQueue(strip *dtos.Strip) error {
+	if err := validateStrip(strip, loadStripOptions()); err != nil {
+		return fmt.Errorf("validateStrip() failed: %w", err)
+	}
+
+	if err := p.checkReferences(strip.Options); err != nil {
+		return fmt.Errorf("p.checkReferences() failed: %w", err)
+	}
+
+	message := &dtos.PhotoMessage{
+		Type:  entities.PhotoMessageStrip,
+		Strip: strip,
+	}
+
+	if err := p.queue.Publish(message); err != nil {
+		return fmt.Errorf("error adding strip to queue: %v", err)
+	}
+
+	return nil
+}
+
+// CreateStrip composes stored photos into strip and creates it as new photo with its own variants
+func (p PhotoConsumeUseCase) CreateStrip(strip *dtos.Strip) (dtos.Photo, error) {
+	ctx := context.Background()
+
+	if err := validateStrip(strip, p.strips); err != nil {
+		return dtos.Photo{}, fmt.Errorf("validateStrip() failed: %w", err)
+	}
+
+	collageOptions, err := p.collageOptions(strip)
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.collageOptions() failed: %w", err)
+	}
+
+	images := make([]image.Image, 0, len(strip.PhotoIDs))
+
+	for _, id := range strip.PhotoIDs {
+		photoDB, err := p.db.FindOne(ctx, id)
+		if err != nil {
+			return dtos.Photo{}, fmt.Errorf("db.FindOne(): %w", err)
+		}
+
+		img, colorProfile, err := decodeStoredOriginal(photoDB)
+		if err != nil {
+			return dtos.Photo{}, fmt.Errorf("decodeStoredOriginal() failed: %w", err)
+		}
+
+		// Photos of different color spaces are combined in sRGB
+		img = toSRGB(img, colorProfile)
+
+		// Photos look in strip same way as in their own variants
+		img, err = p.stages.apply(ctx, img, photoDB.Options)
+		if err != nil {
+			return dtos.Photo{}, fmt.Errorf("p.stages.apply() failed: %w", err)
+		}
+
+		images = append(images, img)
+	}
+
+	data, err := utils.EncodeJPEG(utils.Collage(images, collageOptions), stripQuality)
+	if err != nil {
+		return dtos.Photo{}, fmt.Errorf("utils.EncodeJPEG() failed: %w", err)
+	}
+
+	photo := dtos.Photo{Data: base64.StdEncoding.EncodeToString(data), Options: strip.Options}
+	if err := p.Create(&photo); err != nil {
+		return dtos.Photo{}, fmt.Errorf("p.Create(): %w", err)
+	}
+
+	return photo, nil
+}
+
+func (p PhotoConsumeUseCase) collageOptions(strip *dtos.Strip) (utils.CollageOptions, error) {
+	options := utils.CollageOptions{
+		Layout:     strip.Layout,
+		CellWidth:  p.strips.cellWidth,
+		CellHeight: p.strips.cellHeight,
+		Spacing:    strip.Spacing,
+		Header:     strip.Header,
+		Footer:     strip.Footer,
+	}
+
+	if strip.CellWidth > 0 {
+		options.CellWidth = strip.CellWidth
+	}
+
+	if strip.CellHeight > 0 {
+		options.CellHeight = strip.CellHeight
+	}
+
+	background := strip.Background
+	if background == "" {
+		background = defaultProfileBackground
+	}
+
+	var err error
+
+	options.Background, err = utils.ParseHexColor(background)
+	if err != nil {
+		return options, fmt.Errorf("utils.ParseHexColor() failed: %w", err)
+	}
+
+	if strip.Frame != "" {
+		options.Frame, err = decodeStripFrame(strip.Frame)
+		if err != nil {
+			return options, fmt.Errorf("decodeStripFrame() failed: %w", err)
+		}
+	}
+
+	return options, nil
+}
+
+func validateStrip(strip *dtos.Strip, options stripOptions) error {
+	if len(strip.PhotoIDs) < minStripPhotos || len(strip.PhotoIDs) > options.maxPhotos {
+		return fmt.Errorf("%w: strip takes from %d to %d photos, got %d",
+			customErrors.ErrInvalidStrip, minStripPhotos, options.maxPhotos, len(strip.PhotoIDs))
+	}
+
+	if _, _, ok := utils.CollageGridSize(strip.Layout, len(strip.PhotoIDs)); !ok {
+		return fmt.Errorf("%w: layout %q can't hold %d photos", customErrors.ErrInvalidStrip, strip.Layout, len(strip.PhotoIDs))
+	}
+
+	if strip.Spacing < 0 || strip.Spacing > options.maxSpacing {
+		return fmt.Errorf("%w: spacing must be from 0 to %d", customErrors.ErrInvalidStrip, options.maxSpacing)
+	}
+
+	for name, size := range map[string]int{
+		"header":     strip.Header,
+		"footer":     strip.Footer,
+		"cellWidth":  strip.CellWidth,
+		"cellHeight": strip.CellHeight,
+	} {
+		if size < 0 || size > options.maxCellSize {
+			return fmt.Errorf("%w: %s must be from 0 to %d", customErrors.ErrInvalidStrip, name, options.maxCellSize)
+		}
+	}
+
+	if strip.Background != "" {
+		if _, err := utils.ParseHexColor(strip.Background); err != nil {
+			return fmt.Errorf("%w: %w", customErrors.ErrInvalidStrip, err)
+		}
+	}
+
+	if strip.Frame != "" {
+		if _, err := decodeStripFrame(strip.Frame); err != nil {
+			return fmt.Errorf("%w: %w", customErrors.ErrInvalidStrip, err)
+		}
+	}
+
+	if _, err := filters.ParseAll(strip.Options.Filters); err != nil {
+		return fmt.Errorf("%w: %w", customErrors.ErrInvalidStrip, err)
+	}
+
+	return nil
+}
+
+// decodeStripFrame decodes frame overlay, frame must be PNG to keep photos visible through transparent areas
+func decodeStripFrame(frame string) (image.Image, error) {
+	data, mimeType, err := utils.DecodeB64DataURI(frame)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeB64DataURI() failed: %w", err)
+	}
+
+	if mimeType != utils.MimeTypePNG {
+		return nil, fmt.Errorf("frame must be %s, got %s", utils.MimeTypePNG, mimeType)
+	}
+
+	img, _, err := utils.DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("utils.DecodeImage() failed: %w", err)
+	}
+
+	return img, nil
+}
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// templatePhotoRegion is region photo is placed in, first region is used when template has none of this name
+const templatePhotoRegion = "photo"
+
+// TemplateUseCase manages branded frames library
+type TemplateUseCase struct {
+	db  clients.TemplateStorage
+	log *zerolog.Logger
+}
+
+func NewTemplateUseCase(storage clients.TemplateStorage, l *zerolog.Logger) TemplateUseCase {
+	return TemplateUseCase{
+		db:  storage,
+		log: l,
+	}
+}
+
+// Create stores PNG frame, data is b64 or data URI
+func (t TemplateUseCase) Create(name, data string, regions []dtos.TemplateRegion, caption *dtos.CaptionOptions) (dtos.Template, error) {
+	ctx := context.Background()
+
+	template := dtos.Template{Name: name, Regions: regions, Caption: caption}
+	if err := setTemplateData(&template, data); err != nil {
+		return dtos.Template{}, fmt.Errorf("setTemplateData() failed: %w", err)
+	}
+
+	if err := validateTemplateRegions(template); err != nil {
+		return dtos.Template{}, fmt.Errorf("validateTemplateRegions() failed: %w", err)
+	}
+
+	if err := t.db.Create(ctx, &template); err != nil {
+		return dtos.Template{}, fmt.Errorf("db.Create(): %w", err)
+	}
+
+	// data is echoed back only by GetByID
+	template.Data = ""
+
+	return template, nil
+}
+
+// GetAll lists templates without data
+func (t TemplateUseCase) GetAll() ([]dtos.Template, error) {
+	ctx := context.Background()
+	templates, err := t.db.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindAll(): %w", err)
+	}
+
+	return templates, nil
+}
+
+func (t TemplateUseCase) GetByID(id string) (dtos.Template, error) {
+	ctx := context.Background()
+	template, err := t.db.FindOne(ctx, id)
+	if err != nil {
+		return dtos.Template{}, fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	if template.IsDeleted {
+		return dtos.Template{}, customErrors.ErrTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// Update replaces fields which are set, regions are checked against new or stored frame
+func (t TemplateUseCase) Update(id, name, data string, regions []dtos.TemplateRegion, caption *dtos.CaptionOptions) (dtos.Template, error) {
+	ctx := context.Background()
+
+	template, err := t.db.FindOne(ctx, id)
+	if err != nil {
+		return dtos.Template{}, fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	if template.IsDeleted {
+		return dtos.Template{}, customErrors.ErrTemplateNotFound
+	}
+
+	if name != "" {
+		template.Name = name
+	}
+
+	if data != "" {
+		if err := setTemplateData(&template, data); err != nil {
+			return dtos.Template{}, fmt.Errorf("setTemplateData() failed: %w", err)
+		}
+	}
+
+	if regions != nil {
+		template.Regions = regions
+	}
+
+	if caption != nil {
+		template.Caption = caption
+	}
+
+	if err := validateTemplateRegions(template); err != nil {
+		return dtos.Template{}, fmt.Errorf("validateTemplateRegions() failed: %w", err)
+	}
+
+	if err := t.db.Update(ctx, &template); err != nil {
+		return dtos.Template{}, fmt.Errorf("db.Update(): %w", err)
+	}
+
+	template.Data = ""
+
+	return template, nil
+}
+
+func (t TemplateUseCase) Delete(id string) error {
+	ctx := context.Background()
+	if err := t.db.Delete(ctx, id); err != nil {
+		return fmt.Errorf("db.Delete(): %w", err)
+	}
+
+	return nil
+}
+
+// setTemplateData decodes frame, frame must be PNG to show photo through transparent region
+func setTemplateData(template *dtos.Template, data string) error {
+	decoded, mimeType, err := utils.DecodeB64DataURI(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", customErrors.ErrInvalidTemplate, err)
+	}
+
+	if mimeType != utils.MimeTypePNG {
+		return fmt.Errorf("%w: frame must be %s, got %s", customErrors.ErrInvalidTemplate, utils.MimeTypePNG, mimeType)
+	}
+
+	img, _, err := utils.DecodeImage(decoded)
+	if err != nil {
+		return fmt.Errorf("%w: %w", customErrors.ErrInvalidTemplate, err)
+	}
+
+	template.Width = img.Bounds().Dx()
+	template.Height = img.Bounds().Dy()
+	template.Data = base64.StdEncoding.EncodeToString(decoded)
+
+	return nil
+}
+
+func validateTemplateRegions(template dtos.Template) error {
+	if len(template.Regions) == 0 {
+		return fmt.Errorf("%w: at least one region is required", customErrors.ErrInvalidTemplate)
+	}
+
+	bounds := image.Rect(0, 0, template.Width, template.Height)
+	names := make(map[string]struct{}, len(template.Regions))
+
+	for _, region := range template.Regions {
+		if region.Name == "" {
+			return fmt.Errorf("%w: region name is required", customErrors.ErrInvalidTemplate)
+		}
+
+		if _, ok := names[region.Name]; ok {
+			return fmt.Errorf("%w: duplicate region %s", customErrors.ErrInvalidTemplate, region.Name)
+		}
+
+		names[region.Name] = struct{}{}
+
+		if region.Width <= 0 || region.Height <= 0 || !templateRegionRect(region).In(bounds) {
+			return fmt.Errorf("%w: region %s is out of %dx%d frame", customErrors.ErrInvalidTemplate, region.Name, template.Width, template.Height)
+		}
+	}
+
+	return nil
+}
+
+func templateRegionRect(region dtos.TemplateRegion) image.Rectangle {
+	return image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
+}
+
+// templateRegion returns region named name, first region when template has none of this name
+func templateRegion(template dtos.Template, name string) dtos.TemplateRegion {
+	for _, region := range template.Regions {
+		if region.Name == name {
+			return region
+		}
+	}
+
+	return template.Regions[0]
+}
@@ -0,0 +1,459 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/singleflight"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configTransformAllowlist    = "transform.allowlist"
+	configTransformMaxDimension = "transform.maxDimension"
+)
+
+const (
+	defaultTransformMaxDimension = 4096
+	defaultTransformQuality      = 80
+	maxTransformQuality          = 100
+)
+
+var transformMimeTypes = map[string]string{
+	entities.TransformFormatJPEG: utils.MimeTypeJPEG,
+	entities.TransformFormatPNG:  utils.MimeTypePNG,
+	entities.TransformFormatWebP: utils.MimeTypeWebP,
+}
+
+type PhotoTransformUseCase struct {
+	db           clients.PhotoStorage
+	stages       photoStages
+	processor    utils.ImageProcessor
+	watermark    *watermark
+	color        colorHandling
+	attribution  attributionSettings
+	memoryCache  clients.DerivedCache
+	diskCache    clients.DerivedCache
+	secret       string
+	allowlist    map[string]struct{}
+	maxDimension int
+	group        *singleflight.Group
+	log          *zerolog.Logger
+}
+
+func NewPhotoTransformUseCase(
+	storage clients.PhotoStorage,
+	backgrounds clients.BackgroundStorage,
+	templates clients.TemplateStorage,
+	memoryCache clients.DerivedCache,
+	diskCache clients.DerivedCache,
+	secret string,
+	l *zerolog.Logger,
+) (PhotoTransformUseCase, error) {
+	viper.SetDefault(configTransformMaxDimension, defaultTransformMaxDimension)
+
+	maxDimension := viper.GetInt(configTransformMaxDimension)
+
+	allowed := make([]dtos.TransformParams, 0)
+	if err := viper.UnmarshalKey(configTransformAllowlist, &allowed); err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	allowlist := make(map[string]struct{}, len(allowed))
+
+	for _, params := range allowed {
+		normalized, err := normalizeTransformParams(params, maxDimension)
+		if err != nil {
+			return PhotoTransformUseCase{}, fmt.Errorf("invalid %s entry: %w", configTransformAllowlist, err)
+		}
+
+		allowlist[transformCanonical(normalized)] = struct{}{}
+	}
+
+	stages, err := loadPhotoStages(backgrounds, templates)
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadPhotoStages() failed: %w", err)
+	}
+
+	processor, err := loadImageProcessor()
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadImageProcessor() failed: %w", err)
+	}
+
+	watermarks, err := loadWatermarks()
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadWatermarks() failed: %w", err)
+	}
+
+	defaultWatermark, err := loadDefaultWatermark(watermarks)
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadDefaultWatermark() failed: %w", err)
+	}
+
+	color, err := loadColorHandling()
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadColorHandling() failed: %w", err)
+	}
+
+	attribution, err := loadAttributionSettings()
+	if err != nil {
+		return PhotoTransformUseCase{}, fmt.Errorf("loadAttributionSettings() failed: %w", err)
+	}
+
+	return PhotoTransformUseCase{
+		db:           storage,
+		stages:       stages,
+		processor:    processor,
+		watermark:    defaultWatermark,
+		color:        color,
+		attribution:  attribution,
+		memoryCache:  memoryCache,
+		diskCache:    diskCache,
+		secret:       secret,
+		allowlist:    allowlist,
+		maxDimension: maxDimension,
+		group:        new(singleflight.Group),
+		log:          l,
+	}, nil
+}
+
+// Transform derives image from original photo. Parameter set must be allowlisted in config
+// or signed with transform secret: hex HMAC-SHA256 of "<photo id>\n<canonical params>".
+// When format isn't requested, params are authorized as JPEG and image is served in smallest accepted format
+func (p PhotoTransformUseCase) Transform(id string, params dtos.TransformParams, signature string, accepted []string) (dtos.DerivedImage, error) {
+	ctx := context.Background()
+
+	negotiate := params.Format == ""
+
+	params, err := normalizeTransformParams(params, p.maxDimension)
+	if err != nil {
+		return dtos.DerivedImage{}, err
+	}
+
+	canonical := transformCanonical(params)
+	if !p.isAllowed(id, canonical, signature) {
+		return dtos.DerivedImage{}, customErrors.ErrInvalidSignature
+	}
+
+	formats := []string{params.Format}
+	if negotiate {
+		formats = negotiateTransformFormats(accepted)
+		canonical += "&negotiated=" + strings.Join(formats, ",")
+	}
+
+	// Renders of previous photo version aren't hit again after reprocess, memory LRU evicts them
+	version, err := p.db.FindVersion(ctx, id)
+	if err != nil {
+		return dtos.DerivedImage{}, fmt.Errorf("db.FindVersion(): %w", err)
+	}
+
+	if version.IsDeleted {
+		return dtos.DerivedImage{}, customErrors.ErrPhotoDeleted
+	}
+
+	stagesKey, err := p.stages.cacheKey(ctx, version.Options)
+	if err != nil {
+		return dtos.DerivedImage{}, fmt.Errorf("p.stages.cacheKey() failed: %w", err)
+	}
+
+	canonical += stagesKey
+
+	// Attribution is embedded into every render, so renders with previous attribution config aren't served
+	attribution := p.attribution.resolve(version.Options.Event)
+	canonical += fmt.Sprintf("&attribution=%q,%q,%q", attribution.Creator, attribution.Copyright, attribution.Credit)
+
+	// Renders with previous watermark aren't served after watermark config changes
+	if p.watermark != nil {
+		canonical += "&watermark=" + p.watermark.Name
+	}
+
+	key := transformCacheKey(id, version, canonical)
+
+	// Negotiated format is known after render only, so type is sniffed from data
+	if data, ok := p.cached(ctx, key); ok {
+		return dtos.DerivedImage{MimeType: utils.GetB64MimeType(data), Data: data}, nil
+	}
+
+	// Concurrent requests of same uncached image render it once
+	result, err, _ := p.group.Do(key, func() (any, error) {
+		start := time.Now()
+
+		data, err := p.render(ctx, id, params, formats)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.memoryCache.Set(ctx, key, data); err != nil {
+			p.log.Warn().Err(err).Msg("memoryCache.Set() failed")
+		}
+
+		if err := p.diskCache.Set(ctx, key, data); err != nil {
+			p.log.Warn().Err(err).Msg("diskCache.Set() failed")
+		}
+
+		p.log.Debug().
+			Str("id", id).
+			Str("params", canonical).
+			Dur("render_ms", time.Since(start)).
+			Msg("photo transformed")
+
+		return data, nil
+	})
+	if err != nil {
+		return dtos.DerivedImage{}, err
+	}
+
+	data := result.([]byte)
+
+	return dtos.DerivedImage{MimeType: utils.GetB64MimeType(data), Data: data}, nil
+}
+
+func (p PhotoTransformUseCase) isAllowed(id, canonical, signature string) bool {
+	if _, ok := p.allowlist[canonical]; ok {
+		return true
+	}
+
+	return p.secret != "" && signature != "" && utils.VerifyHMAC(p.secret, signature, id, canonical)
+}
+
+// negotiateTransformFormats lists formats rendered for client which didn't request one, smallest of them is served.
+// Lossless WebP is mostly bigger than JPEG of photo, so it's served only when it turns out smaller.
+// PNG is rendered only for clients accepting neither, JPEG when nothing matches
+func negotiateTransformFormats(accepted []string) []string {
+	formats := make([]string, 0, 2)
+
+	for _, format := range []string{entities.TransformFormatJPEG, entities.TransformFormatWebP} {
+		if slices.Contains(accepted, transformMimeTypes[format]) {
+			formats = append(formats, format)
+		}
+	}
+
+	switch {
+	case len(formats) > 0:
+		return formats
+	case slices.Contains(accepted, utils.MimeTypePNG):
+		return []string{entities.TransformFormatPNG}
+	default:
+		return []string{entities.TransformFormatJPEG}
+	}
+}
+
+// cached looks up memory cache first, disk hits are promoted to memory
+func (p PhotoTransformUseCase) cached(ctx context.Context, key string) ([]byte, bool) {
+	data, err := p.memoryCache.Get(ctx, key)
+	if err == nil {
+		return data, true
+	}
+
+	data, err = p.diskCache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, customErrors.ErrCacheMiss) {
+			p.log.Warn().Err(err).Msg("diskCache.Get() failed")
+		}
+
+		return nil, false
+	}
+
+	if err := p.memoryCache.Set(ctx, key, data); err != nil {
+		p.log.Warn().Err(err).Msg("memoryCache.Set() failed")
+	}
+
+	return data, true
+}
+
+// render derives image and encodes it in every format, smallest encoding is returned
+func (p PhotoTransformUseCase) render(ctx context.Context, id string, params dtos.TransformParams, formats []string) ([]byte, error) {
+	photoDB, err := p.db.FindOne(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("db.FindOne(): %w", err)
+	}
+
+	img, colorProfile, err := decodeStoredOriginal(photoDB)
+	if err != nil {
+		return nil, fmt.Errorf("decodeStoredOriginal() failed: %w", err)
+	}
+
+	// Derived PNG doesn't carry profile, so it's always converted to sRGB. PNG is never rendered with other formats
+	if formats[0] == entities.TransformFormatPNG {
+		img = toSRGB(img, colorProfile)
+		colorProfile = nil
+	}
+
+	img, iccProfile := p.color.prepare(img, colorProfile)
+
+	// Derived image is rendered from what variants are, requested filters are applied on top
+	img, err = p.stages.apply(ctx, img, photoDB.Options)
+	if err != nil {
+		return nil, fmt.Errorf("p.stages.apply() failed: %w", err)
+	}
+
+	// Filters were validated on transform params normalization
+	photoFilters, err := filters.ParseAll(params.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("filters.ParseAll() failed: %w", err)
+	}
+
+	width, height := transformBox(img, params)
+	if width > p.maxDimension || height > p.maxDimension {
+		return nil, fmt.Errorf("%w: derived size %dx%d exceeds %d", customErrors.ErrInvalidTransform, width, height, p.maxDimension)
+	}
+	background, _ := utils.ParseHexColor(defaultProfileBackground)
+
+	transformed := utils.ThumbnailWith(p.processor, img, width, height, params.Fit, background)
+	transformed = filters.Apply(transformed, photoFilters)
+	transformed = applyWatermark(transformed, p.watermark, photoDB.Options)
+
+	embedded := newVariantMetadata(p.attribution.resolve(photoDB.Options.Event), iccProfile)
+
+	var smallest []byte
+
+	for _, format := range formats {
+		data, err := encodeTransform(transformed, format, params.Quality, embedded)
+		if err != nil {
+			return nil, fmt.Errorf("encodeTransform() failed for format %s: %w", format, err)
+		}
+
+		if smallest == nil || len(data) < len(smallest) {
+			smallest = data
+		}
+	}
+
+	return smallest, nil
+}
+
+// encodeTransform encodes derived image with embedded metadata, quality applies to JPEG only
+func encodeTransform(img image.Image, format string, quality int, embedded variantMetadata) ([]byte, error) {
+	switch format {
+	case entities.TransformFormatPNG:
+		data, err := utils.EncodePNG(img)
+		if err != nil {
+			return nil, fmt.Errorf("utils.EncodePNG() failed: %w", err)
+		}
+
+		return data, nil
+	case entities.TransformFormatWebP:
+		data, err := utils.EncodeWebP(img)
+		if err != nil {
+			return nil, fmt.Errorf("utils.EncodeWebP() failed: %w", err)
+		}
+
+		data, err = embedded.embedWebP(data)
+		if err != nil {
+			return nil, fmt.Errorf("embedded.embedWebP() failed: %w", err)
+		}
+
+		return data, nil
+	default:
+		data, err := utils.EncodeJPEG(img, quality)
+		if err != nil {
+			return nil, fmt.Errorf("utils.EncodeJPEG() failed: %w", err)
+		}
+
+		data, err = embedded.embed(data)
+		if err != nil {
+			return nil, fmt.Errorf("embedded.embed() failed: %w", err)
+		}
+
+		return data, nil
+	}
+}
+
+// transformBox fills missing dimension keeping aspect ratio of photo
+func transformBox(img image.Image, params dtos.TransformParams) (int, int) {
+	srcWidth, srcHeight := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	width, height := params.Width, params.Height
+
+	if width == 0 {
+		width = max(1, int(math.Round(float64(height)*srcWidth/srcHeight)))
+	}
+
+	if height == 0 {
+		height = max(1, int(math.Round(float64(width)*srcHeight/srcWidth)))
+	}
+
+	return width, height
+}
+
+// normalizeTransformParams validates params and applies defaults, so equal transformations share cache key
+func normalizeTransformParams(params dtos.TransformParams, maxDimension int) (dtos.TransformParams, error) {
+	if params.Width == 0 && params.Height == 0 {
+		return params, fmt.Errorf("%w: w or h is required", customErrors.ErrInvalidTransform)
+	}
+
+	if params.Width < 0 || params.Height < 0 || params.Width > maxDimension || params.Height > maxDimension {
+		return params, fmt.Errorf("%w: w and h must be 0-%d", customErrors.ErrInvalidTransform, maxDimension)
+	}
+
+	if params.Fit == "" {
+		params.Fit = utils.ResizeModeFit
+	}
+
+	if !utils.IsResizeModeSupported(params.Fit) {
+		return params, fmt.Errorf("%w: unsupported fit %s", customErrors.ErrInvalidTransform, params.Fit)
+	}
+
+	if params.Format == "" {
+		params.Format = entities.TransformFormatJPEG
+	}
+
+	if _, ok := transformMimeTypes[params.Format]; !ok {
+		return params, fmt.Errorf("%w: unsupported format %s", customErrors.ErrInvalidTransform, params.Format)
+	}
+
+	switch {
+	case params.Format != entities.TransformFormatJPEG:
+		params.Quality = 0 // quality doesn't apply to lossless formats
+	case params.Quality == 0:
+		params.Quality = defaultTransformQuality
+	case params.Quality < 1 || params.Quality > maxTransformQuality:
+		return params, fmt.Errorf("%w: q must be 1-100", customErrors.ErrInvalidTransform)
+	}
+
+	requested, err := filters.ParseAll(params.Filters)
+	if err != nil {
+		return params, fmt.Errorf("%w: %w", customErrors.ErrInvalidTransform, err)
+	}
+
+	params.Filters = make([]string, 0, len(requested))
+	for _, filter := range requested {
+		params.Filters = append(params.Filters, filter.String())
+	}
+
+	return params, nil
+}
+
+// transformCanonical is stable text form of normalized params, used for signatures, allowlist and cache keys
+func transformCanonical(params dtos.TransformParams) string {
+	canonical := fmt.Sprintf("w=%d&h=%d&fit=%s&format=%s&q=%d", params.Width, params.Height, params.Fit, params.Format, params.Quality)
+	if len(params.Filters) > 0 {
+		canonical += "&filters=" + strings.Join(params.Filters, ",")
+	}
+
+	return canonical
+}
+
+// transformCacheKey changes with photo version and render inputs in canonical,
+// so reprocessed photos and photos with updated template aren't served from stale renders
+func transformCacheKey(id string, version dtos.PhotoVersion, canonical string) string {
+	sum := sha256.Sum256([]byte(id + "\n" + strconv.FormatInt(version.UpdatedAt.UnixNano(), 10) + "\n" + canonical))
+
+	return hex.EncodeToString(sum[:])
+}
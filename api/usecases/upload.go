@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configUploadsTTL     = "uploads.ttlSec"
+	configUploadsMaxSize = "uploads.maxSizeBytes"
+)
+
+const (
+	defaultUploadsTTL     = 900
+	defaultUploadsMaxSize = 50 << 20
+
+	uploadIDBytes = 16
+	sniffLen      = 512 // bytes considered by http.DetectContentType
+	uploadsPath   = "/api/photo/uploads/"
+)
+
+// Signature purposes, so upload url can't be used to finalize and vice versa
+const (
+	uploadPurposeUpload   = "upload"
+	uploadPurposeFinalize = "finalize"
+)
+
+type PhotoUploadUseCase struct {
+	storage clients.UploadStorage
+	queue   clients.PhotoQueue
+	secret  string
+	ttl     time.Duration
+	maxSize int64
+	log     *zerolog.Logger
+}
+
+func NewPhotoUploadUseCase(storage clients.UploadStorage, queue clients.PhotoQueue, secret string, l *zerolog.Logger) PhotoUploadUseCase {
+	viper.SetDefault(configUploadsTTL, defaultUploadsTTL)
+	viper.SetDefault(configUploadsMaxSize, defaultUploadsMaxSize)
+
+	return PhotoUploadUseCase{
+		storage: storage,
+		queue:   queue,
+		secret:  secret,
+		ttl:     time.Duration(viper.GetInt(configUploadsTTL)) * time.Second,
+		maxSize: viper.GetInt64(configUploadsMaxSize),
+		log:     l,
+	}
+}
+
+// CreateUpload issues short-lived signed urls for uploading photo bytes and finalizing upload
+func (p PhotoUploadUseCase) CreateUpload() (dtos.Upload, error) {
+	ctx := context.Background()
+
+	// Slots which were never finalized are dropped lazily
+	if err := p.storage.DeleteOlderThan(ctx, time.Now().Add(-p.ttl)); err != nil {
+		p.log.Warn().Err(err).Msg("storage.DeleteOlderThan() failed")
+	}
+
+	idBytes := make([]byte, uploadIDBytes)
+	if _, err := rand.Read(idBytes); err != nil {
+		return dtos.Upload{}, fmt.Errorf("rand.Read() failed: %w", err)
+	}
+
+	id := hex.EncodeToString(idBytes)
+	expiresAt := time.Now().Add(p.ttl).UTC().Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return dtos.Upload{
+		ID:          id,
+		UploadURL:   uploadsPath + id + "?" + p.signedQuery(uploadPurposeUpload, id, expires),
+		FinalizeURL: uploadsPath + id + "/finalize?" + p.signedQuery(uploadPurposeFinalize, id, expires),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (p PhotoUploadUseCase) signedQuery(purpose, id, expires string) string {
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", utils.SignHMAC(p.secret, purpose, id, expires))
+
+	return query.Encode()
+}
+
+func (p PhotoUploadUseCase) Upload(id, expires, signature string, data io.Reader) error {
+	if err := p.verify(uploadPurposeUpload, id, expires, signature); err != nil {
+		return err
+	}
+
+	if err := p.storage.Save(context.Background(), id, data, p.maxSize); err != nil {
+		return fmt.Errorf("storage.Save(): %w", err)
+	}
+
+	return nil
+}
+
+// Finalize adds uploaded photo in queue by upload id, consumer reads photo from upload storage and deletes it.
+// Upload is finalized only once
+func (p PhotoUploadUseCase) Finalize(id, expires, signature string) error {
+	ctx := context.Background()
+
+	if err := p.verify(uploadPurposeFinalize, id, expires, signature); err != nil {
+		return err
+	}
+
+	mimeType, err := p.sniffMimeType(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsSupportedPhotoMimeType(mimeType) {
+		return fmt.Errorf("%w: %s", customErrors.ErrUnsupportedPhotoType, mimeType)
+	}
+
+	if err := p.storage.MarkFinalized(ctx, id); err != nil {
+		return fmt.Errorf("storage.MarkFinalized(): %w", err)
+	}
+
+	message := &dtos.PhotoMessage{
+		Type:     entities.PhotoMessageUpload,
+		UploadID: id,
+	}
+
+	if err := p.queue.Publish(message); err != nil {
+		// Upload stays in place, so client may retry finalization
+		if err := p.storage.UnmarkFinalized(ctx, id); err != nil {
+			p.log.Error().Err(err).Msgf("storage.UnmarkFinalized() failed for upload: %s", id)
+		}
+
+		return fmt.Errorf("error adding photo to queue: %w", err)
+	}
+
+	return nil
+}
+
+// sniffMimeType reads only photo header, upload itself may be large
+func (p PhotoUploadUseCase) sniffMimeType(ctx context.Context, id string) (string, error) {
+	upload, err := p.storage.Open(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("storage.Open(): %w", err)
+	}
+	defer func() {
+		if err := upload.Close(); err != nil {
+			p.log.Error().Err(customErrors.ErrorOsCloseFailed).Err(err).Send()
+		}
+	}()
+
+	header, err := io.ReadAll(io.LimitReader(upload, sniffLen))
+	if err != nil {
+		return "", fmt.Errorf("io.ReadAll() failed: %w", err)
+	}
+
+	return utils.GetB64MimeType(header), nil
+}
+
+func (p PhotoUploadUseCase) verify(purpose, id, expires, signature string) error {
+	if _, err := hex.DecodeString(id); err != nil || len(id) != uploadIDBytes*2 {
+		return customErrors.ErrUploadNotFound
+	}
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return customErrors.ErrInvalidSignature
+	}
+
+	if !utils.VerifyHMAC(p.secret, signature, purpose, id, expires) {
+		return customErrors.ErrInvalidSignature
+	}
+
+	if time.Now().Unix() > expiresUnix {
+		return customErrors.ErrUploadExpired
+	}
+
+	return nil
+}
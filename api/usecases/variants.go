@@ -0,0 +1,271 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"regexp"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/filters"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configVariantProfiles = "variants.profiles"
+)
+
+const (
+	defaultProfileQuality    = 80
+	defaultProfileMinQuality = 30
+	defaultProfileBackground = "#FFFFFF"
+	maxProfileQuality        = 100
+)
+
+var variantProfileNameRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+var variantMimeTypes = map[string]string{
+	entities.VariantFormatJPEG: utils.MimeTypeJPEG,
+	entities.VariantFormatWebP: utils.MimeTypeWebP,
+}
+
+// variantProfile is config profile with resolved watermark
+type variantProfile struct {
+	dtos.VariantProfile
+	watermark *watermark
+	filters   []filters.Filter
+	processor utils.ImageProcessor
+}
+
+// loadVariantProfiles reads and validates variant profiles from config, defaults are applied to empty fields.
+// Profiles without resampler are resized with processor
+func loadVariantProfiles(watermarks map[string]*watermark, processor utils.ImageProcessor) ([]variantProfile, error) {
+	profiles := make([]dtos.VariantProfile, 0)
+	if err := viper.UnmarshalKey(configVariantProfiles, &profiles); err != nil {
+		return nil, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(profiles))
+
+	for i := range profiles {
+		profile := &profiles[i]
+
+		if !variantProfileNameRegexp.MatchString(profile.Name) {
+			return nil, fmt.Errorf("invalid variant profile name: %q", profile.Name)
+		}
+
+		if _, ok := names[profile.Name]; ok {
+			return nil, fmt.Errorf("duplicated variant profile: %s", profile.Name)
+		}
+
+		names[profile.Name] = struct{}{}
+
+		if profile.Width <= 0 || profile.Height <= 0 {
+			return nil, fmt.Errorf("variant profile %s: width and height must be positive", profile.Name)
+		}
+
+		if profile.Mode == "" {
+			profile.Mode = utils.ResizeModeFit
+		}
+
+		if !utils.IsResizeModeSupported(profile.Mode) {
+			return nil, fmt.Errorf("variant profile %s: unsupported mode %s", profile.Name, profile.Mode)
+		}
+
+		if profile.Background == "" {
+			profile.Background = defaultProfileBackground
+		}
+
+		if _, err := utils.ParseHexColor(profile.Background); err != nil {
+			return nil, fmt.Errorf("variant profile %s: %w", profile.Name, err)
+		}
+
+		if profile.Quality == 0 {
+			profile.Quality = defaultProfileQuality
+		}
+
+		if profile.Quality < 1 || profile.Quality > maxProfileQuality {
+			return nil, fmt.Errorf("variant profile %s: quality must be 1-100", profile.Name)
+		}
+
+		if profile.MaxBytes < 0 {
+			return nil, fmt.Errorf("variant profile %s: maxBytes must be positive", profile.Name)
+		}
+
+		if profile.MinQuality == 0 {
+			profile.MinQuality = min(defaultProfileMinQuality, profile.Quality)
+		}
+
+		if profile.MinQuality < 1 || profile.MinQuality > profile.Quality {
+			return nil, fmt.Errorf("variant profile %s: minQuality must be from 1 to quality", profile.Name)
+		}
+
+		if len(profile.Formats) == 0 {
+			profile.Formats = []string{entities.VariantFormatJPEG}
+		}
+
+		if err := validateVariantFormats(profile.Formats); err != nil {
+			return nil, fmt.Errorf("variant profile %s: %w", profile.Name, err)
+		}
+	}
+
+	resolved := make([]variantProfile, 0, len(profiles))
+
+	for _, profile := range profiles {
+		var profileWatermark *watermark
+
+		if profile.Watermark != "" {
+			found, ok := watermarks[profile.Watermark]
+			if !ok {
+				return nil, fmt.Errorf("variant profile %s: unknown watermark %s", profile.Name, profile.Watermark)
+			}
+
+			profileWatermark = found
+		}
+
+		profileFilters, err := filters.ParseAll(profile.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("variant profile %s: %w", profile.Name, err)
+		}
+
+		profileProcessor := processor
+		if profile.Resampler != "" {
+			profileProcessor, err = utils.NewImageProcessor(profile.Resampler)
+			if err != nil {
+				return nil, fmt.Errorf("variant profile %s: %w", profile.Name, err)
+			}
+		}
+
+		resolved = append(resolved, variantProfile{
+			VariantProfile: profile,
+			watermark:      profileWatermark,
+			filters:        profileFilters,
+			processor:      profileProcessor,
+		})
+	}
+
+	return resolved, nil
+}
+
+// validateVariantFormats checks formats are supported and JPEG is among them, it's served to clients without WebP support
+func validateVariantFormats(formats []string) error {
+	seen := make(map[string]struct{}, len(formats))
+
+	for _, format := range formats {
+		if _, ok := variantMimeTypes[format]; !ok {
+			return fmt.Errorf("unsupported format %s", format)
+		}
+
+		if _, ok := seen[format]; ok {
+			return fmt.Errorf("duplicated format %s", format)
+		}
+
+		seen[format] = struct{}{}
+	}
+
+	if _, ok := seen[entities.VariantFormatJPEG]; !ok {
+		return fmt.Errorf("formats must include %s", entities.VariantFormatJPEG)
+	}
+
+	return nil
+}
+
+// renderProfile generates variant of profile in every profile format from decoded photo,
+// watermark is skipped when photo opted out
+func renderProfile(img image.Image, embedded variantMetadata, profile variantProfile, options dtos.ProcessingOptions, log *zerolog.Logger) ([]dtos.PhotoVariant, error) {
+	background, err := utils.ParseHexColor(profile.Background)
+	if err != nil {
+		return nil, fmt.Errorf("utils.ParseHexColor() failed: %w", err)
+	}
+
+	thumbnail := utils.ThumbnailWith(profile.processor, img, profile.Width, profile.Height, profile.Mode, background)
+	thumbnail = filters.Apply(thumbnail, profile.filters)
+	thumbnail = applyWatermark(thumbnail, profile.watermark, options)
+
+	variants := make([]dtos.PhotoVariant, 0, len(profile.Formats))
+
+	for _, format := range profile.Formats {
+		data, quality, err := encodeProfileFormat(thumbnail, format, profile, embedded, log)
+		if err != nil {
+			return nil, fmt.Errorf("encodeProfileFormat() failed for format %s: %w", format, err)
+		}
+
+		variants = append(variants, dtos.PhotoVariant{
+			Name:     profile.Name,
+			MimeType: variantMimeTypes[format],
+			Width:    thumbnail.Bounds().Dx(),
+			Height:   thumbnail.Bounds().Dy(),
+			Quality:  quality,
+			Size:     len(data),
+			Data:     base64.StdEncoding.EncodeToString(data),
+		})
+	}
+
+	return variants, nil
+}
+
+// encodeProfileFormat encodes variant in format with embedded metadata. WebP is lossless,
+// so quality and byte budget apply to JPEG only, embedded metadata counts towards the budget
+func encodeProfileFormat(img image.Image, format string, profile variantProfile, embedded variantMetadata, log *zerolog.Logger) ([]byte, int, error) {
+	if format == entities.VariantFormatWebP {
+		data, err := utils.EncodeWebP(img)
+		if err != nil {
+			return nil, 0, fmt.Errorf("utils.EncodeWebP() failed: %w", err)
+		}
+
+		data, err = embedded.embedWebP(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("embedded.embedWebP() failed: %w", err)
+		}
+
+		return data, 0, nil
+	}
+
+	if profile.MaxBytes > 0 {
+		profile.MaxBytes = max(profile.MaxBytes-embedded.size(), 1)
+	}
+
+	data, quality, err := encodeProfile(img, profile, log)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encodeProfile() failed: %w", err)
+	}
+
+	data, err = embedded.embed(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("embedded.embed() failed: %w", err)
+	}
+
+	return data, quality, nil
+}
+
+// encodeProfile encodes variant with profile quality, or with highest quality fitting profile byte budget
+func encodeProfile(img image.Image, profile variantProfile, log *zerolog.Logger) ([]byte, int, error) {
+	if profile.MaxBytes == 0 {
+		data, err := utils.EncodeJPEG(img, profile.Quality)
+		if err != nil {
+			return nil, 0, fmt.Errorf("utils.EncodeJPEG() failed: %w", err)
+		}
+
+		return data, profile.Quality, nil
+	}
+
+	data, quality, fits, err := utils.EncodeJPEGWithin(img, profile.MaxBytes, profile.MinQuality, profile.Quality)
+	if err != nil {
+		return nil, 0, fmt.Errorf("utils.EncodeJPEGWithin() failed: %w", err)
+	}
+
+	if !fits {
+		log.Warn().
+			Str("profile", profile.Name).
+			Int("size", len(data)).
+			Int("max_bytes", profile.MaxBytes).
+			Msg("variant exceeds byte budget at min quality")
+	}
+
+	return data, quality, nil
+}
@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configWatermarkProfiles = "watermarks.profiles"
+	configWatermarkDefault  = "watermarks.default"
+)
+
+const (
+	defaultWatermarkOpacity = 1
+	defaultWatermarkScale   = 0.2
+)
+
+type watermark struct {
+	dtos.WatermarkProfile
+	overlay image.Image
+}
+
+// loadWatermarks reads watermark profiles from config and decodes their PNG overlays, mapped by name
+func loadWatermarks() (map[string]*watermark, error) {
+	profiles := make([]dtos.WatermarkProfile, 0)
+	if err := viper.UnmarshalKey(configWatermarkProfiles, &profiles); err != nil {
+		return nil, fmt.Errorf("viper.UnmarshalKey() failed: %w", err)
+	}
+
+	watermarks := make(map[string]*watermark, len(profiles))
+
+	for _, profile := range profiles {
+		if !variantProfileNameRegexp.MatchString(profile.Name) {
+			return nil, fmt.Errorf("invalid watermark profile name: %q", profile.Name)
+		}
+
+		if _, ok := watermarks[profile.Name]; ok {
+			return nil, fmt.Errorf("duplicated watermark profile: %s", profile.Name)
+		}
+
+		if profile.Position == "" {
+			profile.Position = utils.WatermarkBottomRight
+		}
+
+		if !utils.IsWatermarkPositionSupported(profile.Position) {
+			return nil, fmt.Errorf("watermark profile %s: unsupported position %s", profile.Name, profile.Position)
+		}
+
+		if profile.Opacity == 0 {
+			profile.Opacity = defaultWatermarkOpacity
+		}
+
+		if profile.Scale == 0 {
+			profile.Scale = defaultWatermarkScale
+		}
+
+		if profile.Opacity < 0 || profile.Opacity > 1 || profile.Scale < 0 || profile.Scale > 1 || profile.Margin < 0 {
+			return nil, fmt.Errorf("watermark profile %s: opacity and scale must be 0-1, margin positive", profile.Name)
+		}
+
+		overlay, err := loadWatermarkOverlay(profile.Source)
+		if err != nil {
+			return nil, fmt.Errorf("watermark profile %s: %w", profile.Name, err)
+		}
+
+		watermarks[profile.Name] = &watermark{WatermarkProfile: profile, overlay: overlay}
+	}
+
+	return watermarks, nil
+}
+
+// loadDefaultWatermark resolves watermark of percentage variants and transformed images, nil when none is set
+func loadDefaultWatermark(watermarks map[string]*watermark) (*watermark, error) {
+	name := viper.GetString(configWatermarkDefault)
+	if name == "" {
+		return nil, nil
+	}
+
+	found, ok := watermarks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown %s watermark %s", configWatermarkDefault, name)
+	}
+
+	return found, nil
+}
+
+func loadWatermarkOverlay(source string) (image.Image, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile() failed: %w", err)
+	}
+
+	overlay, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("png.Decode() failed: %w", err)
+	}
+
+	return overlay, nil
+}
+
+func (w *watermark) apply(img image.Image) image.Image {
+	return utils.Watermark(img, w.overlay, w.Position, w.Margin, w.Opacity, w.Scale)
+}
+
+// applyWatermark draws watermark unless there is none or photo opted out
+func applyWatermark(img image.Image, w *watermark, options dtos.ProcessingOptions) image.Image {
+	if w == nil || options.NoWatermark {
+		return img
+	}
+
+	return w.apply(img)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"test-task-photo-booth/api"
@@ -54,8 +55,17 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to create rabbitmq consumer")
 	}
 
+	//Run CLI subcommand instead of server
+	if len(os.Args) > 1 && os.Args[1] == reprocessCommand {
+		if err := runReprocess(os.Args[2:], postgresClient, rabbitmqClient, log); err != nil {
+			log.Fatal().Err(err).Msg("reprocess failed")
+		}
+
+		return
+	}
+
 	//Attach routes
-	routes := api.NewRouter(postgresClient, rabbitmqClient, log)
+	routes := api.NewRouter(configs, postgresClient, rabbitmqClient, log)
 
 	log.Info().Msg("server started")
 
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/adapters/queue/rmq"
+	"test-task-photo-booth/api/usecases"
+	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+const reprocessCommand = "reprocess"
+
+// runReprocess starts reprocess job same way as POST /api/admin/photos/reprocess and reports progress until it's done:
+//
+//	producer reprocess -all
+//	producer reprocess -ids 1f0c...,8a2b...
+//	producer reprocess -camera-make Canon -captured-from 2024-06-01T00:00:00Z
+func runReprocess(
+	args []string,
+	postgresClient *pgxpool.Pool,
+	rabbitmqClient *rabbitmq.RabbitMqClient,
+	log *zerolog.Logger,
+) error {
+	flags := flag.NewFlagSet(reprocessCommand, flag.ContinueOnError)
+
+	ids := flags.String("ids", "", "comma separated photo ids")
+	all := flags.Bool("all", false, "reprocess all photos")
+	cameraMake := flags.String("camera-make", "", "filter by camera make")
+	cameraModel := flags.String("camera-model", "", "filter by camera model")
+	capturedFrom := flags.String("captured-from", "", "filter by capture time from, RFC3339")
+	capturedTo := flags.String("captured-to", "", "filter by capture time to, RFC3339")
+	hasGPS := flags.String("has-gps", "", "filter by gps presence, true or false")
+	wait := flags.Bool("wait", true, "report progress until job is done")
+	poll := flags.Duration("poll", 2*time.Second, "progress report interval")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("flags.Parse() failed: %w", err)
+	}
+
+	reprocess := dtos.Reprocess{All: *all}
+
+	if *ids != "" {
+		reprocess.PhotoIDs = strings.Split(*ids, ",")
+	}
+
+	filter := dtos.PhotoFilter{CameraMake: *cameraMake, CameraModel: *cameraModel}
+	isFiltered := *cameraMake != "" || *cameraModel != ""
+
+	for value, target := range map[string]**time.Time{
+		*capturedFrom: &filter.CapturedFrom,
+		*capturedTo:   &filter.CapturedTo,
+	} {
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid capture time: %w", err)
+		}
+
+		*target = &parsed
+		isFiltered = true
+	}
+
+	if *hasGPS != "" {
+		value, err := strconv.ParseBool(*hasGPS)
+		if err != nil {
+			return fmt.Errorf("invalid has-gps: %w", err)
+		}
+
+		filter.HasGPS = &value
+		isFiltered = true
+	}
+
+	if isFiltered {
+		reprocess.Filter = &filter
+	}
+
+	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
+	reprocessJobCollection := postgres.NewReprocessJobStoragePG(postgresClient, log)
+	reprocessQueue := rmq.NewPhotoProducer(rabbitmqClient.Conn, rabbitmqClient.ReprocessQueue, log)
+
+	reprocessUseCase := usecases.NewReprocessUseCase(photoCollection, reprocessJobCollection, reprocessQueue, log)
+
+	job, err := reprocessUseCase.Start(reprocess)
+	if err != nil {
+		return fmt.Errorf("reprocessUseCase.Start(): %w", err)
+	}
+
+	log.Info().Msgf("reprocess job id=%s, photos=%d", job.ID, job.Total)
+
+	for *wait && job.Status != entities.ReprocessStatusDone {
+		time.Sleep(*poll)
+
+		job, err = reprocessUseCase.GetJob(job.ID)
+		if err != nil {
+			return fmt.Errorf("reprocessUseCase.GetJob(): %w", err)
+		}
+
+		log.Info().Msgf("reprocess job id=%s, processed=%d, failed=%d, total=%d",
+			job.ID, job.Processed, job.Failed, job.Total)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"test-task-photo-booth/api/adapters/queue/rmq"
+	"test-task-photo-booth/pkg/clients/rabbitmq"
+	"test-task-photo-booth/pkg/hotfolder"
+	"test-task-photo-booth/pkg/logger"
+	"test-task-photo-booth/src/config"
+)
+
+const loggerName = "watcher"
+
+func main() {
+	//Init project and env configs
+	configs, err := config.GetConfig()
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("failed to load .env")
+	}
+
+	//Setup main logger with level
+	log, err := logger.SetServiceLogger(loggerName, configs)
+	if err != nil {
+		log.Fatal().Err(err).Msg("logger.SetMainLogger failed")
+	}
+
+	//Add queue
+	rabbitConn, err := rabbitmq.NewRabbitMqConnection(configs.RabbitMQConf)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create rabbitmq connection")
+	}
+
+	rabbitmqClient, err := rabbitmq.NewRabbitMqClient(rabbitConn, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create rabbitmq client")
+	}
+
+	photoQueue := rmq.NewPhotoProducer(rabbitmqClient.Conn, rabbitmqClient.PhotoQueue, log)
+
+	hotFolder, err := hotfolder.NewHotFolder(photoQueue, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("hotfolder.NewHotFolder() failed")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serviceVersion := "v1.0.0"
+
+	log.Info().Msgf("watcher version=%s", serviceVersion)
+	log.Info().Msg("watcher started successfully")
+
+	if err := hotFolder.Watch(ctx); err != nil {
+		log.Fatal().Err(err).Msg("hotFolder.Watch() failed")
+	}
+}
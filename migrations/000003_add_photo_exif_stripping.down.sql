@@ -0,0 +1,2 @@
+ALTER TABLE service.photos
+    DROP COLUMN IF EXISTS removed_exif_tags;
\ No newline at end of file
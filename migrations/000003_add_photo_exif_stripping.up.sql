@@ -0,0 +1,2 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS removed_exif_tags TEXT[] NOT NULL DEFAULT '{}';
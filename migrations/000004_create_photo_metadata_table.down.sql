@@ -0,0 +1 @@
+DROP TABLE IF EXISTS service.photo_metadata;
\ No newline at end of file
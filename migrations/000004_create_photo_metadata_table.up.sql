@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS service.photo_metadata
+(
+    photo_id      UUID PRIMARY KEY REFERENCES service.photos (id) ON DELETE CASCADE,
+    width         INTEGER NOT NULL,
+    height        INTEGER NOT NULL,
+    captured_at   TIMESTAMPTZ,
+    camera_make   TEXT,
+    camera_model  TEXT,
+    lens          TEXT,
+    exposure_time TEXT,
+    f_number      DOUBLE PRECISION,
+    iso           INTEGER,
+    focal_length  DOUBLE PRECISION,
+    gps_latitude  DOUBLE PRECISION,
+    gps_longitude DOUBLE PRECISION
+);
+ALTER TABLE service.photo_metadata
+    OWNER TO "serviceadmin";
+
+CREATE INDEX IF NOT EXISTS photo_metadata_captured_at_idx ON service.photo_metadata (captured_at);
+CREATE INDEX IF NOT EXISTS photo_metadata_camera_idx ON service.photo_metadata (camera_make, camera_model);
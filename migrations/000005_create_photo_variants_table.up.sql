@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS service.photo_variants
+(
+    photo_id   UUID    NOT NULL REFERENCES service.photos (id) ON DELETE CASCADE,
+    name       TEXT    NOT NULL,
+    mime_type  TEXT    NOT NULL,
+    width      INTEGER NOT NULL,
+    height     INTEGER NOT NULL,
+    quality    INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    data       TEXT    NOT NULL,
+    PRIMARY KEY (photo_id, name)
+);
+ALTER TABLE service.photo_variants
+    OWNER TO "serviceadmin";
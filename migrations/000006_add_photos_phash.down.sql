@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS service.photos_phash_bands_idx;
+
+ALTER TABLE service.photos
+    DROP COLUMN IF EXISTS duplicate_of,
+    DROP COLUMN IF EXISTS phash_bands,
+    DROP COLUMN IF EXISTS phash;
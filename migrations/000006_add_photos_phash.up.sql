@@ -0,0 +1,7 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS phash        BIGINT,
+    ADD COLUMN IF NOT EXISTS phash_bands  INTEGER[],
+    ADD COLUMN IF NOT EXISTS duplicate_of UUID REFERENCES service.photos (id);
+
+-- Near-duplicates within small distance share at least one exact 16 bit band of hash
+CREATE INDEX IF NOT EXISTS photos_phash_bands_idx ON service.photos USING GIN (phash_bands);
@@ -0,0 +1,3 @@
+ALTER TABLE service.photos
+    DROP COLUMN IF EXISTS palette,
+    DROP COLUMN IF EXISTS blurhash;
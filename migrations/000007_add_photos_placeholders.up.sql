@@ -0,0 +1,3 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS blurhash TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS palette  TEXT[] NOT NULL DEFAULT '{}';
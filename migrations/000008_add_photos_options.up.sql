@@ -0,0 +1,2 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
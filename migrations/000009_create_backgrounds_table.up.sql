@@ -0,0 +1,14 @@
+-- Photos keep rendering with deleted backgrounds, so they are only hidden from library
+CREATE TABLE IF NOT EXISTS service.backgrounds
+(
+    id         UUID PRIMARY KEY DEFAULT service.gen_random_uuid(),
+    name       TEXT        NOT NULL,
+    mime_type  TEXT        NOT NULL,
+    width      INTEGER     NOT NULL,
+    height     INTEGER     NOT NULL,
+    data       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    is_deleted BOOLEAN     NOT NULL DEFAULT FALSE
+);
+ALTER TABLE service.backgrounds
+    OWNER TO "serviceadmin";
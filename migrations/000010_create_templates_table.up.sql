@@ -0,0 +1,15 @@
+-- Photos keep rendering with deleted templates, so they are only hidden from library
+CREATE TABLE IF NOT EXISTS service.templates
+(
+    id         UUID PRIMARY KEY DEFAULT service.gen_random_uuid(),
+    name       TEXT        NOT NULL,
+    width      INTEGER     NOT NULL,
+    height     INTEGER     NOT NULL,
+    regions    JSONB       NOT NULL DEFAULT '[]',
+    data       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    is_deleted BOOLEAN     NOT NULL DEFAULT FALSE
+);
+ALTER TABLE service.templates
+    OWNER TO "serviceadmin";
@@ -0,0 +1,2 @@
+ALTER TABLE service.templates
+    DROP COLUMN IF EXISTS caption;
@@ -0,0 +1,2 @@
+ALTER TABLE service.templates
+    ADD COLUMN IF NOT EXISTS caption JSONB;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS service.reprocess_jobs
+(
+    id         UUID PRIMARY KEY DEFAULT service.gen_random_uuid(),
+    total      INTEGER     NOT NULL,
+    processed  INTEGER     NOT NULL DEFAULT 0,
+    failed     INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE service.reprocess_jobs
+    OWNER TO "serviceadmin";
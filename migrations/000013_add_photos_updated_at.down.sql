@@ -0,0 +1,2 @@
+ALTER TABLE service.photos
+    DROP COLUMN IF EXISTS updated_at;
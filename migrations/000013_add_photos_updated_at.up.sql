@@ -0,0 +1,2 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,3 @@
+ALTER TABLE service.photos
+    DROP COLUMN IF EXISTS quality_flags,
+    DROP COLUMN IF EXISTS quality_scores;
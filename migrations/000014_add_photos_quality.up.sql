@@ -0,0 +1,3 @@
+ALTER TABLE service.photos
+    ADD COLUMN IF NOT EXISTS quality_scores JSONB,
+    ADD COLUMN IF NOT EXISTS quality_flags  TEXT[] NOT NULL DEFAULT '{}';
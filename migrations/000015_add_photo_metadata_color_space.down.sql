@@ -0,0 +1,2 @@
+ALTER TABLE service.photo_metadata
+    DROP COLUMN IF EXISTS color_space;
@@ -0,0 +1,2 @@
+ALTER TABLE service.photo_metadata
+    ADD COLUMN IF NOT EXISTS color_space TEXT;
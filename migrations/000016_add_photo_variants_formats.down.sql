@@ -0,0 +1,6 @@
+DELETE FROM service.photo_variants
+WHERE mime_type <> 'image/jpeg';
+
+ALTER TABLE service.photo_variants
+    DROP CONSTRAINT IF EXISTS photo_variants_pkey,
+    ADD PRIMARY KEY (photo_id, name);
@@ -0,0 +1,3 @@
+ALTER TABLE service.photo_variants
+    DROP CONSTRAINT IF EXISTS photo_variants_pkey,
+    ADD PRIMARY KEY (photo_id, name, mime_type);
@@ -2,18 +2,76 @@ package clients
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"test-task-photo-booth/src/entities/dtos"
 )
 
 type PhotoStorage interface {
 	Create(ctx context.Context, photo *dtos.PhotoDB) error
-	FindAll(ctx context.Context) ([]dtos.PhotoDB, error)
+	FindAll(ctx context.Context, filter dtos.PhotoFilter) ([]dtos.PhotoDB, error)
+	FindIDs(ctx context.Context, filter dtos.PhotoFilter) ([]string, error)
 	FindOne(ctx context.Context, id string) (dtos.PhotoDB, error)
+	FindVersion(ctx context.Context, id string) (dtos.PhotoVersion, error)
+	FindMetadata(ctx context.Context, id string) (dtos.PhotoMetadata, error)
+	FindVariant(ctx context.Context, photoID, name, mimeType string) (dtos.PhotoVariant, error)
+	FindVariants(ctx context.Context, photoID string) ([]dtos.PhotoVariant, error)
+	FindSimilar(ctx context.Context, hash uint64, threshold, limit int) ([]dtos.SimilarPhoto, error)
+	FindQualityScores(ctx context.Context, ids []string) ([]dtos.BurstPhoto, error)
 	Update(ctx context.Context, photo dtos.PhotoDB) error
 	Delete(ctx context.Context, id string) error
 }
 
 type PhotoQueue interface {
-	Publish(photo *dtos.Photo) error
+	Publish(message *dtos.PhotoMessage) error
+}
+
+type PhotoFetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// UploadStorage keeps photos uploaded directly via presigned url until upload is finalized
+type UploadStorage interface {
+	Save(ctx context.Context, id string, data io.Reader, maxSize int64) error
+	Load(ctx context.Context, id string) ([]byte, error)
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	Delete(ctx context.Context, id string) error
+	DeleteOlderThan(ctx context.Context, before time.Time) error
+	// MarkFinalized atomically marks upload finalized, returns customErrors.ErrUploadFinalized for marked one.
+	// Save refuses marked uploads
+	MarkFinalized(ctx context.Context, id string) error
+	UnmarkFinalized(ctx context.Context, id string) error
+}
+
+// DerivedCache keeps photos rendered by transformations, Get returns customErrors.ErrCacheMiss when key is absent
+type DerivedCache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte) error
+}
+
+// BackgroundStorage keeps backgrounds library for chroma key, FindAll lists backgrounds without data
+type BackgroundStorage interface {
+	Create(ctx context.Context, background *dtos.Background) error
+	FindAll(ctx context.Context) ([]dtos.Background, error)
+	FindOne(ctx context.Context, id string) (dtos.Background, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// TemplateStorage keeps branded frames, FindAll lists templates without data
+type TemplateStorage interface {
+	Create(ctx context.Context, template *dtos.Template) error
+	FindAll(ctx context.Context) ([]dtos.Template, error)
+	FindOne(ctx context.Context, id string) (dtos.Template, error)
+	// FindVersion returns updated_at of template without loading its frame
+	FindVersion(ctx context.Context, id string) (time.Time, error)
+	Update(ctx context.Context, template *dtos.Template) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ReprocessJobStorage keeps reprocess jobs progress, shared by producer starting jobs and consumer running them
+type ReprocessJobStorage interface {
+	Create(ctx context.Context, job *dtos.ReprocessJob) error
+	FindOne(ctx context.Context, id string) (dtos.ReprocessJob, error)
+	AddProgress(ctx context.Context, id string, processed, failed int) error
 }
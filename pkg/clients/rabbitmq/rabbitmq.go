@@ -1,6 +1,7 @@
 package rabbitmq
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -8,8 +9,11 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
 
 	"test-task-photo-booth/api/adapters/db/postgres"
+	"test-task-photo-booth/api/adapters/remote"
+	"test-task-photo-booth/api/adapters/storage/disk"
 	"test-task-photo-booth/api/usecases"
 	"test-task-photo-booth/pkg/logger"
 	"test-task-photo-booth/src/config"
@@ -38,9 +42,10 @@ func NewRabbitMqConnection(configs config.RabbitMQConf) (*amqp.Connection, error
 type PhotoQueue *amqp.Queue
 
 type RabbitMqClient struct {
-	Conn       *amqp.Connection
-	PhotoQueue PhotoQueue
-	log        *zerolog.Logger
+	Conn           *amqp.Connection
+	PhotoQueue     PhotoQueue
+	ReprocessQueue PhotoQueue
+	log            *zerolog.Logger
 }
 
 func NewRabbitMqClient(conn *amqp.Connection, log *zerolog.Logger) (*RabbitMqClient, error) {
@@ -68,7 +73,13 @@ func (c *RabbitMqClient) setupChannels() error {
 		return fmt.Errorf("failed to declare photo queue: %w", err)
 	}
 
+	reprocessQueue, err := declareQueue(ch, entities.ReprocessQueue)
+	if err != nil {
+		return fmt.Errorf("failed to declare reprocess queue: %w", err)
+	}
+
 	c.PhotoQueue = photoQueue
+	c.ReprocessQueue = reprocessQueue
 
 	return nil
 }
@@ -93,7 +104,20 @@ func declareQueue(ch *amqp.Channel, name string) (*amqp.Queue, error) {
 
 const restartTimer = 20
 
+const contentTypeJSON = "application/json"
+
 func (c *RabbitMqClient) Listen(postgresClient *pgxpool.Pool, log *zerolog.Logger) error {
+	//Reprocessing is consumed separately, so regenerating old photos never delays new ones
+	go func() {
+		for {
+			err := c.reprocessQueue(postgresClient, log)
+			if err != nil {
+				log.Error().Err(err).Msgf("c.reprocessQueue failed; RESTART in %v sec", restartTimer)
+				time.Sleep(time.Second * restartTimer)
+			}
+		}
+	}()
+
 	//Add queues listeners
 	for {
 		err := c.photoQueue(postgresClient, log)
@@ -113,7 +137,32 @@ func (c *RabbitMqClient) photoQueue(postgresClient *pgxpool.Pool, log *zerolog.L
 	}()
 
 	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
-	photoUseCase := usecases.NewPhotoConsumeUseCase(photoCollection, log)
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
+
+	photoFetcher, err := remote.NewPhotoFetcherHTTP(log)
+	if err != nil {
+		return fmt.Errorf("remote.NewPhotoFetcherHTTP() failed: %w", err)
+	}
+
+	viper.SetDefault(entities.ConfigUploadsDir, entities.DefaultUploadsDir)
+
+	uploadStorage, err := disk.NewUploadStorageDisk(viper.GetString(entities.ConfigUploadsDir), log)
+	if err != nil {
+		return fmt.Errorf("disk.NewUploadStorageDisk() failed: %w", err)
+	}
+
+	photoUseCase, err := usecases.NewPhotoConsumeUseCase(
+		photoCollection,
+		backgroundCollection,
+		templateCollection,
+		photoFetcher,
+		uploadStorage,
+		log,
+	)
+	if err != nil {
+		return fmt.Errorf("usecases.NewPhotoConsumeUseCase() failed: %w", err)
+	}
 
 	ch, err := c.Conn.Channel()
 	if err != nil {
@@ -137,9 +186,14 @@ func (c *RabbitMqClient) photoQueue(postgresClient *pgxpool.Pool, log *zerolog.L
 
 	go func() {
 		for m := range messages {
-			if err := photoUseCase.Create(&dtos.Photo{Data: string(m.Body)}); err != nil {
-				logger.Log.Error().Msgf("failed to create photo with body: %s", string(m.Body))
+			message, err := decodePhotoMessage(m)
+			if err != nil {
+				logger.Log.Error().Err(err).Msg("failed to decode photo message")
+
+				continue
 			}
+
+			handlePhotoMessage(photoUseCase, message, log)
 		}
 	}()
 
@@ -147,3 +201,147 @@ func (c *RabbitMqClient) photoQueue(postgresClient *pgxpool.Pool, log *zerolog.L
 
 	return nil
 }
+
+// reprocessQueue takes one photo at a time with manual ack, so backlog waits in broker and survives consumer restart
+func (c *RabbitMqClient) reprocessQueue(postgresClient *pgxpool.Pool, log *zerolog.Logger) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Info().Msgf("recovered from panic: %v; RESTART in %v sec", r, restartTimer)
+			time.Sleep(time.Second * restartTimer)
+		}
+	}()
+
+	photoCollection := postgres.NewPhotoStoragePG(postgresClient, log)
+	backgroundCollection := postgres.NewBackgroundStoragePG(postgresClient, log)
+	templateCollection := postgres.NewTemplateStoragePG(postgresClient, log)
+	reprocessJobCollection := postgres.NewReprocessJobStoragePG(postgresClient, log)
+
+	reprocessUseCase, err := usecases.NewReprocessConsumeUseCase(
+		photoCollection,
+		backgroundCollection,
+		templateCollection,
+		reprocessJobCollection,
+		log,
+	)
+	if err != nil {
+		return fmt.Errorf("usecases.NewReprocessConsumeUseCase() failed: %w", err)
+	}
+
+	ch, err := c.Conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(1, 0, false); err != nil {
+		return fmt.Errorf("failed to set channel qos: %w", err)
+	}
+
+	messages, err := ch.Consume(
+		c.ReprocessQueue.Name, // Queue name
+		"",                    // consumer tag (empty string means a unique tag will be generated)
+		false,                 // auto-ack (messages are acknowledged once photo is reprocessed)
+		false,                 // exclusive (only this consumer can access the queue)
+		false,                 // no-local (if true, the server will not deliver messages to the connection that published them)
+		false,                 // no-wait (do not wait for a server response)
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	for m := range messages {
+		message, err := decodePhotoMessage(m)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode reprocess message")
+		} else if message.Type != entities.PhotoMessageReprocess {
+			log.Error().Msgf("unexpected reprocess message type: %s", message.Type)
+		} else if err := reprocessUseCase.Reprocess(message.JobID, message.PhotoID); err != nil {
+			log.Error().Err(err).Str("job_id", message.JobID).Msgf("failed to reprocess photo, id: %s", message.PhotoID)
+		}
+
+		// Failed photo is counted in job progress, redelivery would count it twice
+		if err := m.Ack(false); err != nil {
+			return fmt.Errorf("failed to ack message: %w", err)
+		}
+	}
+
+	return fmt.Errorf("reprocess deliveries channel closed")
+}
+
+// decodePhotoMessage supports json messages and legacy plain text ones, which contain only b64 photo data
+func decodePhotoMessage(m amqp.Delivery) (dtos.PhotoMessage, error) {
+	if m.ContentType != contentTypeJSON {
+		return dtos.PhotoMessage{Type: entities.PhotoMessageUpload, Data: string(m.Body)}, nil
+	}
+
+	var message dtos.PhotoMessage
+	if err := json.Unmarshal(m.Body, &message); err != nil {
+		return message, fmt.Errorf("json.Unmarshal() failed: %w", err)
+	}
+
+	return message, nil
+}
+
+func handlePhotoMessage(photoUseCase usecases.PhotoConsumeUseCase, message dtos.PhotoMessage, log *zerolog.Logger) {
+	switch message.Type {
+	case entities.PhotoMessageUpload:
+		if message.UploadID == "" {
+			if err := photoUseCase.Create(&dtos.Photo{Data: message.Data, Options: message.Options}); err != nil {
+				log.Error().Err(err).Msg("failed to create photo")
+			}
+
+			return
+		}
+
+		photo, err := photoUseCase.CreateFromUpload(message.UploadID, message.Options)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to create photo from upload: %s", message.UploadID)
+
+			return
+		}
+
+		log.Info().Msgf("photo created from upload: %s, id: %s", message.UploadID, photo.ID)
+	case entities.PhotoMessageImport:
+		photo, err := photoUseCase.Import(message.SourceURL, message.Options)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to import photo from url: %s", message.SourceURL)
+
+			return
+		}
+
+		log.Info().Msgf("photo imported from url: %s, id: %s", message.SourceURL, photo.ID)
+	case entities.PhotoMessageStrip:
+		if message.Strip == nil {
+			log.Error().Msg("strip message without strip")
+
+			return
+		}
+
+		photo, err := photoUseCase.CreateStrip(message.Strip)
+		if err != nil {
+			log.Error().Err(err).Strs("photo_ids", message.Strip.PhotoIDs).Msg("failed to create strip")
+
+			return
+		}
+
+		log.Info().Strs("photo_ids", message.Strip.PhotoIDs).Msgf("strip created, id: %s", photo.ID)
+	case entities.PhotoMessageBoomerang:
+		if message.Boomerang == nil {
+			log.Error().Msg("boomerang message without boomerang")
+
+			return
+		}
+
+		photo, err := photoUseCase.CreateBoomerang(message.Boomerang)
+		if err != nil {
+			log.Error().Err(err).Strs("photo_ids", message.Boomerang.PhotoIDs).Msg("failed to create boomerang")
+
+			return
+		}
+
+		log.Info().Strs("photo_ids", message.Boomerang.PhotoIDs).Msgf("boomerang created, id: %s", photo.ID)
+	default:
+		log.Error().Msgf("unknown photo message type: %s", message.Type)
+	}
+}
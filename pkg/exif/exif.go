@@ -0,0 +1,236 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// TIFF field types
+const (
+	TypeByte      uint16 = 1
+	TypeASCII     uint16 = 2
+	TypeShort     uint16 = 3
+	TypeLong      uint16 = 4
+	TypeRational  uint16 = 5
+	TypeUndefined uint16 = 7
+	TypeSLong     uint16 = 9
+	TypeSRational uint16 = 10
+	TypeFloat     uint16 = 11
+	TypeDouble    uint16 = 12
+)
+
+const (
+	tiffHeaderSize = 8
+	ifdEntrySize   = 12
+	tiffMagic      = 42
+
+	maxIFDEntries = 1000
+)
+
+var (
+	ErrInvalidTIFF = errors.New("invalid tiff data")
+	ErrNoExif      = errors.New("no exif data")
+)
+
+// Entry is single IFD field, Value is kept raw in Exif byte order
+type Entry struct {
+	Tag   uint16
+	Type  uint16
+	Count uint32
+	Value []byte
+}
+
+// IFD is ordered list of fields
+type IFD []Entry
+
+// Exif is parsed TIFF structure of EXIF APP1 segment.
+// Thumbnail IFD1 is not kept, it's dropped on serialization
+type Exif struct {
+	ByteOrder binary.ByteOrder
+	IFD0      IFD
+	ExifIFD   IFD
+	GPSIFD    IFD
+	Interop   IFD
+}
+
+func typeSize(t uint16) uint32 {
+	switch t {
+	case TypeByte, TypeASCII, TypeUndefined:
+		return 1
+	case TypeShort:
+		return 2
+	case TypeLong, TypeSLong, TypeFloat:
+		return 4
+	case TypeRational, TypeSRational, TypeDouble:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// Parse parses TIFF data from EXIF APP1 segment (without "Exif\0\0" header)
+func Parse(tiff []byte) (*Exif, error) {
+	if len(tiff) < tiffHeaderSize {
+		return nil, ErrInvalidTIFF
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, ErrInvalidTIFF
+	}
+
+	if order.Uint16(tiff[2:4]) != tiffMagic {
+		return nil, ErrInvalidTIFF
+	}
+
+	e := &Exif{ByteOrder: order}
+
+	ifd0, err := parseIFD(tiff, order, order.Uint32(tiff[4:8]))
+	if err != nil {
+		return nil, fmt.Errorf("IFD0: %w", err)
+	}
+
+	e.IFD0 = ifd0.withoutPointers()
+
+	if offset, ok := ifd0.pointer(order, TagExifIFDPointer); ok {
+		exifIFD, err := parseIFD(tiff, order, offset)
+		if err != nil {
+			return nil, fmt.Errorf("exif IFD: %w", err)
+		}
+
+		e.ExifIFD = exifIFD.withoutPointers()
+
+		if offset, ok := exifIFD.pointer(order, TagInteropIFDPointer); ok {
+			// interop is optional, broken one is just skipped
+			if interop, err := parseIFD(tiff, order, offset); err == nil {
+				e.Interop = interop
+			}
+		}
+	}
+
+	if offset, ok := ifd0.pointer(order, TagGPSIFDPointer); ok {
+		gpsIFD, err := parseIFD(tiff, order, offset)
+		if err != nil {
+			return nil, fmt.Errorf("GPS IFD: %w", err)
+		}
+
+		e.GPSIFD = gpsIFD
+	}
+
+	return e, nil
+}
+
+func parseIFD(tiff []byte, order binary.ByteOrder, offset uint32) (IFD, error) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, ErrInvalidTIFF
+	}
+
+	count := uint32(order.Uint16(tiff[offset:]))
+	if count > maxIFDEntries || uint64(offset)+2+uint64(count)*ifdEntrySize > uint64(len(tiff)) {
+		return nil, ErrInvalidTIFF
+	}
+
+	ifd := make(IFD, 0, count)
+
+	for i := uint32(0); i < count; i++ {
+		raw := tiff[offset+2+i*ifdEntrySize:]
+
+		entry := Entry{
+			Tag:   order.Uint16(raw[0:2]),
+			Type:  order.Uint16(raw[2:4]),
+			Count: order.Uint32(raw[4:8]),
+		}
+
+		size := uint64(typeSize(entry.Type)) * uint64(entry.Count)
+		if size == 0 {
+			continue // unknown type, can't be copied safely
+		}
+
+		if size <= 4 {
+			entry.Value = append([]byte(nil), raw[8:8+size]...)
+		} else {
+			valueOffset := uint64(order.Uint32(raw[8:12]))
+			if valueOffset+size > uint64(len(tiff)) {
+				continue // value points outside of segment
+			}
+
+			entry.Value = append([]byte(nil), tiff[valueOffset:valueOffset+size]...)
+		}
+
+		ifd = append(ifd, entry)
+	}
+
+	return ifd, nil
+}
+
+func (ifd IFD) pointer(order binary.ByteOrder, tag uint16) (uint32, bool) {
+	for _, entry := range ifd {
+		if entry.Tag == tag && len(entry.Value) == 4 {
+			return order.Uint32(entry.Value), true
+		}
+	}
+
+	return 0, false
+}
+
+func (ifd IFD) withoutPointers() IFD {
+	result := make(IFD, 0, len(ifd))
+
+	for _, entry := range ifd {
+		switch entry.Tag {
+		case TagExifIFDPointer, TagGPSIFDPointer, TagInteropIFDPointer:
+			continue
+		default:
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// Find returns first entry with tag
+func (ifd IFD) Find(tag uint16) (Entry, bool) {
+	for _, entry := range ifd {
+		if entry.Tag == tag {
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// Set replaces entry with same tag or appends it
+func (ifd IFD) Set(entry Entry) IFD {
+	for i := range ifd {
+		if ifd[i].Tag == entry.Tag {
+			ifd[i] = entry
+
+			return ifd
+		}
+	}
+
+	return append(ifd, entry)
+}
+
+// Remove drops entries matching tags, returns kept and removed entries
+func (ifd IFD) Remove(match func(tag uint16) bool) (IFD, IFD) {
+	kept := make(IFD, 0, len(ifd))
+	removed := make(IFD, 0)
+
+	for _, entry := range ifd {
+		if match(entry.Tag) {
+			removed = append(removed, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+
+	return kept, removed
+}
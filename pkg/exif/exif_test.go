@@ -0,0 +1,442 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"math"
+	"slices"
+	"testing"
+)
+
+func rationals(order binary.ByteOrder, values ...uint32) Entry {
+	raw := make([]byte, 4*len(values))
+	for i, value := range values {
+		order.PutUint32(raw[i*4:], value)
+	}
+
+	return Entry{Type: TypeRational, Count: uint32(len(values) / 2), Value: raw}
+}
+
+func gpsIFD(order binary.ByteOrder, latitudeRef, longitudeRef string) IFD {
+	// 55°45'21" and 37°37'1.5"
+	latitude := rationals(order, 55, 1, 45, 1, 21, 1)
+	latitude.Tag = TagGPSLatitude
+	longitude := rationals(order, 37, 1, 37, 1, 15, 10)
+	longitude.Tag = TagGPSLongitude
+
+	ifd := IFD{latitude, longitude}
+	if latitudeRef != "" {
+		ifd = append(ifd, ASCIIEntry(TagGPSLatitudeRef, latitudeRef))
+	}
+
+	if longitudeRef != "" {
+		ifd = append(ifd, ASCIIEntry(TagGPSLongitudeRef, longitudeRef))
+	}
+
+	return ifd
+}
+
+func sampleExif(order binary.ByteOrder) *Exif {
+	e := &Exif{ByteOrder: order}
+	e.IFD0 = IFD{
+		ASCIIEntry(TagMake, "Canon"),
+		ASCIIEntry(TagModel, "EOS R6"),
+		e.ShortEntry(TagOrientation, 6),
+		ASCIIEntry(TagSoftware, "booth 1.0"),
+	}
+	e.ExifIFD = IFD{
+		ASCIIEntry(TagDateTimeOriginal, "2024:05:01 18:30:00"),
+		ASCIIEntry(TagBodySerialNumber, "012345678901"),
+		ASCIIEntry(TagLensModel, "RF24-105mm F4 L IS USM"),
+	}
+	e.GPSIFD = gpsIFD(order, "N", "E")
+
+	return e
+}
+
+func sampleJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func tags(ifd IFD) []uint16 {
+	result := make([]uint16, 0, len(ifd))
+	for _, entry := range ifd {
+		result = append(result, entry.Tag)
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func TestParseStripSerialize(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		source := sampleExif(order)
+
+		withExif, err := ReplaceJPEG(sampleJPEG(t), source.Bytes())
+		if err != nil {
+			t.Fatalf("%s: ReplaceJPEG: %v", order, err)
+		}
+
+		tiff, err := ExtractJPEG(withExif)
+		if err != nil {
+			t.Fatalf("%s: ExtractJPEG: %v", order, err)
+		}
+
+		parsed, err := Parse(tiff)
+		if err != nil {
+			t.Fatalf("%s: Parse: %v", order, err)
+		}
+
+		if !slices.Equal(tags(parsed.IFD0), tags(source.IFD0)) || !slices.Equal(tags(parsed.ExifIFD), tags(source.ExifIFD)) ||
+			!slices.Equal(tags(parsed.GPSIFD), tags(source.GPSIFD)) {
+			t.Fatalf("%s: parsed tags differ from serialized ones", order)
+		}
+
+		if lens, _ := parsed.StringTag(parsed.ExifIFD, TagLensModel); lens != "RF24-105mm F4 L IS USM" {
+			t.Errorf("%s: lens model %q", order, lens)
+		}
+
+		removed := parsed.Strip(StripSensitive)
+		for _, name := range []string{"Make", "Model", "BodySerialNumber", "LensModel", "GPSLatitude", "GPSLongitudeRef"} {
+			if !slices.Contains(removed, name) {
+				t.Errorf("%s: %s isn't reported as removed: %v", order, name, removed)
+			}
+		}
+
+		stripped, err := Parse(parsed.Bytes())
+		if err != nil {
+			t.Fatalf("%s: Parse stripped: %v", order, err)
+		}
+
+		if _, ok := stripped.IFD0.Find(TagMake); ok {
+			t.Errorf("%s: Make kept", order)
+		}
+
+		if _, ok := stripped.ExifIFD.Find(TagBodySerialNumber); ok {
+			t.Errorf("%s: BodySerialNumber kept", order)
+		}
+
+		if len(stripped.GPSIFD) != 0 {
+			t.Errorf("%s: GPS kept", order)
+		}
+
+		if stripped.Orientation() != 6 {
+			t.Errorf("%s: orientation %d, want 6", order, stripped.Orientation())
+		}
+
+		if software, _ := stripped.StringTag(stripped.IFD0, TagSoftware); software != "booth 1.0" {
+			t.Errorf("%s: software %q", order, software)
+		}
+
+		if _, ok := stripped.DateTimeOriginal(); !ok {
+			t.Errorf("%s: capture time dropped", order)
+		}
+	}
+}
+
+func TestStripAll(t *testing.T) {
+	e := sampleExif(binary.LittleEndian)
+	e.Strip(StripAll)
+
+	parsed, err := Parse(e.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(tags(parsed.IFD0), []uint16{TagOrientation}) || len(parsed.ExifIFD) != 0 || len(parsed.GPSIFD) != 0 {
+		t.Errorf("got IFD0 %v, exif %v, GPS %v, want orientation only", tags(parsed.IFD0), tags(parsed.ExifIFD), tags(parsed.GPSIFD))
+	}
+}
+
+func TestStripNone(t *testing.T) {
+	e := sampleExif(binary.BigEndian)
+
+	if removed := e.Strip(StripNone); len(removed) != 0 {
+		t.Errorf("removed %v", removed)
+	}
+
+	if len(e.GPSIFD) == 0 {
+		t.Error("GPS removed")
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		long := make([]byte, 4)
+		order.PutUint32(long, 8)
+
+		e := &Exif{ByteOrder: order}
+
+		cases := []struct {
+			name  string
+			ifd   IFD
+			value int
+		}{
+			{name: "missing", ifd: nil, value: OrientationNormal},
+			{name: "short", ifd: IFD{e.ShortEntry(TagOrientation, 6)}, value: 6},
+			{name: "long", ifd: IFD{{Tag: TagOrientation, Type: TypeLong, Count: 1, Value: long}}, value: 8},
+			{name: "zero", ifd: IFD{e.ShortEntry(TagOrientation, 0)}, value: OrientationNormal},
+			{name: "out of range", ifd: IFD{e.ShortEntry(TagOrientation, 9)}, value: OrientationNormal},
+			{name: "ascii", ifd: IFD{ASCIIEntry(TagOrientation, "6")}, value: OrientationNormal},
+		}
+
+		for _, c := range cases {
+			e.IFD0 = c.ifd
+
+			parsed, err := Parse(e.Bytes())
+			if err != nil {
+				t.Fatalf("%s %s: %v", order, c.name, err)
+			}
+
+			if got := parsed.Orientation(); got != c.value {
+				t.Errorf("%s %s: orientation %d, want %d", order, c.name, got, c.value)
+			}
+		}
+	}
+}
+
+func TestGPSSign(t *testing.T) {
+	const (
+		latitude  = 55 + 45.0/60 + 21.0/3600
+		longitude = 37 + 37.0/60 + 1.5/3600
+		epsilon   = 1e-9
+	)
+
+	cases := []struct {
+		latitudeRef, longitudeRef string
+		latitude, longitude       float64
+	}{
+		{"N", "E", latitude, longitude},
+		{"S", "W", -latitude, -longitude},
+		{"S", "E", -latitude, longitude},
+		{"n", "w", latitude, -longitude},
+		{"s", "", -latitude, longitude},
+		{"", "", latitude, longitude},
+	}
+
+	for _, c := range cases {
+		e := &Exif{ByteOrder: binary.BigEndian, GPSIFD: gpsIFD(binary.BigEndian, c.latitudeRef, c.longitudeRef)}
+
+		parsed, err := Parse(e.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lat, lon, ok := parsed.GPS()
+		if !ok {
+			t.Fatalf("%s/%s: no GPS", c.latitudeRef, c.longitudeRef)
+		}
+
+		if math.Abs(lat-c.latitude) > epsilon || math.Abs(lon-c.longitude) > epsilon {
+			t.Errorf("%s/%s: got %f, %f, want %f, %f", c.latitudeRef, c.longitudeRef, lat, lon, c.latitude, c.longitude)
+		}
+	}
+}
+
+func TestGPSInvalid(t *testing.T) {
+	order := binary.LittleEndian
+
+	zeroDenominator := rationals(order, 55, 0, 45, 1, 21, 1)
+	zeroDenominator.Tag = TagGPSLatitude
+	twoParts := rationals(order, 55, 1, 45, 1)
+	twoParts.Tag = TagGPSLatitude
+
+	for name, ifd := range map[string]IFD{
+		"zero denominator": {zeroDenominator, gpsIFD(order, "", "")[1]},
+		"two parts":        {twoParts, gpsIFD(order, "", "")[1]},
+		"no longitude":     {gpsIFD(order, "", "")[0]},
+	} {
+		e := &Exif{ByteOrder: order, GPSIFD: ifd}
+		if _, _, ok := e.GPS(); ok {
+			t.Errorf("%s: GPS reported", name)
+		}
+	}
+}
+
+// tiffHeader returns little endian TIFF header with IFD0 at offset 8
+func tiffHeader() []byte {
+	return []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+}
+
+func ifdEntry(tag, typ uint16, count, value uint32) []byte {
+	raw := make([]byte, ifdEntrySize)
+	binary.LittleEndian.PutUint16(raw[0:], tag)
+	binary.LittleEndian.PutUint16(raw[2:], typ)
+	binary.LittleEndian.PutUint32(raw[4:], count)
+	binary.LittleEndian.PutUint32(raw[8:], value)
+
+	return raw
+}
+
+func tiffWithEntries(count uint16, entries ...[]byte) []byte {
+	tiff := binary.LittleEndian.AppendUint16(tiffHeader(), count)
+	for _, entry := range entries {
+		tiff = append(tiff, entry...)
+	}
+
+	return binary.LittleEndian.AppendUint32(tiff, 0)
+}
+
+func repeated(entry []byte, count int) [][]byte {
+	entries := make([][]byte, count)
+	for i := range entries {
+		entries[i] = entry
+	}
+
+	return entries
+}
+
+func TestParseMalformed(t *testing.T) {
+	orientation := ifdEntry(TagOrientation, TypeShort, 1, 6)
+
+	cases := map[string][]byte{
+		"empty":               nil,
+		"short header":        []byte{'I', 'I', 42, 0},
+		"byte order":          []byte{'X', 'X', 42, 0, 8, 0, 0, 0, 0, 0},
+		"magic":               []byte{'I', 'I', 43, 0, 8, 0, 0, 0, 0, 0},
+		"IFD0 outside":        []byte{'I', 'I', 42, 0, 0xFF, 0xFF, 0, 0},
+		"IFD0 offset at end":  []byte{'I', 'I', 42, 0, 8, 0, 0, 0},
+		"oversized count":     tiffWithEntries(0xFFFF, orientation),
+		"count over limit":    tiffWithEntries(maxIFDEntries+1, repeated(orientation, maxIFDEntries+1)...),
+		"truncated entries":   tiffWithEntries(3, orientation),
+		"exif IFD outside":    tiffWithEntries(1, ifdEntry(TagExifIFDPointer, TypeLong, 1, 0xFFFF)),
+		"GPS IFD truncated":   tiffWithEntries(1, ifdEntry(TagGPSIFDPointer, TypeLong, 1, 25)),
+		"IFD0 offset wraps":   []byte{'I', 'I', 42, 0, 0xFF, 0xFF, 0xFF, 0xFF},
+		"exif IFD offset max": tiffWithEntries(1, ifdEntry(TagExifIFDPointer, TypeLong, 1, 0xFFFFFFFF)),
+	}
+
+	for name, tiff := range cases {
+		if _, err := Parse(tiff); !errors.Is(err, ErrInvalidTIFF) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidTIFF)
+		}
+	}
+}
+
+func TestParseSkipsBrokenEntries(t *testing.T) {
+	tiff := tiffWithEntries(4,
+		ifdEntry(TagMake, TypeASCII, 0xFFFFFFFF, 30),  // value count runs past segment
+		ifdEntry(TagModel, TypeASCII, 16, 0xFFFFFFF0), // value offset outside of segment
+		ifdEntry(TagSoftware, 0xFF, 1, 0),             // unknown type
+		ifdEntry(TagOrientation, TypeShort, 1, 3),
+	)
+
+	e, err := Parse(tiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(tags(e.IFD0), []uint16{TagOrientation}) {
+		t.Errorf("got tags %v, want orientation only", tags(e.IFD0))
+	}
+
+	if e.Orientation() != 3 {
+		t.Errorf("orientation %d, want 3", e.Orientation())
+	}
+}
+
+func TestParseSkipsBrokenInterop(t *testing.T) {
+	e := sampleExif(binary.LittleEndian)
+	e.Interop = IFD{ASCIIEntry(0x0001, "R98")}
+
+	tiff := e.Bytes()
+
+	parsed, err := Parse(tiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed.Interop) != 1 {
+		t.Fatalf("interop %v", parsed.Interop)
+	}
+
+	// Interop pointer of exif IFD is redirected outside of segment
+	ifd0, err := parseIFD(tiff, binary.LittleEndian, tiffHeaderSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exifOffset, ok := ifd0.pointer(binary.LittleEndian, TagExifIFDPointer)
+	if !ok {
+		t.Fatal("exif IFD pointer not found")
+	}
+
+	count := int(binary.LittleEndian.Uint16(tiff[exifOffset:]))
+	for i := 0; i < count; i++ {
+		raw := tiff[int(exifOffset)+2+i*ifdEntrySize:]
+		if binary.LittleEndian.Uint16(raw) == TagInteropIFDPointer {
+			binary.LittleEndian.PutUint32(raw[8:], 0xFFFFFF)
+		}
+	}
+
+	parsed, err = Parse(tiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Interop != nil || len(parsed.ExifIFD) != len(e.ExifIFD) {
+		t.Errorf("broken interop isn't skipped: interop %v, exif %v", parsed.Interop, tags(parsed.ExifIFD))
+	}
+}
+
+func TestReadSegmentsMalformed(t *testing.T) {
+	valid := sampleJPEG(t)
+
+	cases := map[string][]byte{
+		"empty":            nil,
+		"no SOI":           valid[2:],
+		"truncated":        valid[:20],
+		"no SOS":           []byte{0xFF, MarkerSOI, 0xFF, MarkerAPP0, 0, 4, 0, 0},
+		"oversized length": []byte{0xFF, MarkerSOI, 0xFF, MarkerAPP1, 0xFF, 0xFF, 0, 0},
+		"length below 2":   []byte{0xFF, MarkerSOI, 0xFF, MarkerAPP1, 0, 1, 0, 0},
+		"garbage marker":   []byte{0xFF, MarkerSOI, 0x00, MarkerAPP1, 0, 4, 0, 0},
+	}
+
+	for name, data := range cases {
+		if _, _, err := ReadSegments(data); !errors.Is(err, ErrInvalidJPEG) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidJPEG)
+		}
+	}
+
+	if _, err := ExtractJPEG(valid); !errors.Is(err, ErrNoExif) {
+		t.Errorf("got %v, want %v", err, ErrNoExif)
+	}
+}
+
+func TestReplaceJPEGRemovesExif(t *testing.T) {
+	withExif, err := ReplaceJPEG(sampleJPEG(t), sampleExif(binary.LittleEndian).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withoutExif, err := ReplaceJPEG(withExif, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ExtractJPEG(withoutExif); !errors.Is(err, ErrNoExif) {
+		t.Errorf("got %v, want %v", err, ErrNoExif)
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(withoutExif)); err != nil {
+		t.Errorf("jpeg.Decode: %v", err)
+	}
+}
+
+func TestWriteSegmentsTooLarge(t *testing.T) {
+	segments := []Segment{{Marker: MarkerAPP1, Data: make([]byte, MaxSegmentSize+1)}}
+
+	if _, err := WriteSegments(segments, []byte{0xFF, MarkerSOS}); !errors.Is(err, ErrSegmentTooLarge) {
+		t.Errorf("got %v, want %v", err, ErrSegmentTooLarge)
+	}
+}
@@ -0,0 +1,142 @@
+package exif
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// JPEG markers
+const (
+	MarkerSOI  byte = 0xD8
+	MarkerSOS  byte = 0xDA
+	MarkerAPP0 byte = 0xE0
+	MarkerAPP1 byte = 0xE1
+	MarkerAPP2 byte = 0xE2
+
+	markerPrefix byte = 0xFF
+
+	// MaxSegmentSize is max payload of single segment, length field includes itself
+	MaxSegmentSize = 0xFFFF - 2
+)
+
+var (
+	ErrInvalidJPEG     = errors.New("invalid jpeg data")
+	ErrSegmentTooLarge = errors.New("jpeg segment too large")
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// Segment is JPEG marker segment before image scan, Data excludes marker and length
+type Segment struct {
+	Marker byte
+	Data   []byte
+}
+
+// ReadSegments splits JPEG into metadata segments and remaining scan data (starting with SOS marker)
+func ReadSegments(data []byte) ([]Segment, []byte, error) {
+	if len(data) < 4 || data[0] != markerPrefix || data[1] != MarkerSOI {
+		return nil, nil, ErrInvalidJPEG
+	}
+
+	segments := make([]Segment, 0)
+	pos := 2
+
+	for pos+4 <= len(data) {
+		if data[pos] != markerPrefix {
+			return nil, nil, ErrInvalidJPEG
+		}
+
+		marker := data[pos+1]
+		if marker == markerPrefix { // fill byte
+			pos++
+
+			continue
+		}
+
+		if marker == MarkerSOS {
+			return segments, data[pos:], nil
+		}
+
+		length := int(data[pos+2])<<8 | int(data[pos+3])
+		if length < 2 || pos+2+length > len(data) {
+			return nil, nil, ErrInvalidJPEG
+		}
+
+		segments = append(segments, Segment{Marker: marker, Data: data[pos+4 : pos+2+length]})
+		pos += 2 + length
+	}
+
+	return nil, nil, ErrInvalidJPEG
+}
+
+// WriteSegments joins segments and scan data back into JPEG
+func WriteSegments(segments []Segment, scan []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.Write([]byte{markerPrefix, MarkerSOI})
+
+	for _, segment := range segments {
+		if len(segment.Data) > MaxSegmentSize {
+			return nil, fmt.Errorf("%w: marker 0x%X", ErrSegmentTooLarge, segment.Marker)
+		}
+
+		length := len(segment.Data) + 2
+		buf.Write([]byte{markerPrefix, segment.Marker, byte(length >> 8), byte(length)})
+		buf.Write(segment.Data)
+	}
+
+	buf.Write(scan)
+
+	return buf.Bytes(), nil
+}
+
+func isExifSegment(segment Segment) bool {
+	return segment.Marker == MarkerAPP1 && bytes.HasPrefix(segment.Data, exifHeader)
+}
+
+// ExtractJPEG returns TIFF data of EXIF APP1 segment
+func ExtractJPEG(data []byte) ([]byte, error) {
+	segments, _, err := ReadSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments {
+		if isExifSegment(segment) {
+			return segment.Data[len(exifHeader):], nil
+		}
+	}
+
+	return nil, ErrNoExif
+}
+
+// ReplaceJPEG replaces EXIF APP1 segment of JPEG with tiff data, nil tiff removes EXIF completely
+func ReplaceJPEG(data, tiff []byte) ([]byte, error) {
+	segments, scan, err := ReadSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Segment, 0, len(segments)+1)
+	inserted := tiff == nil
+
+	for _, segment := range segments {
+		if isExifSegment(segment) {
+			continue
+		}
+
+		// EXIF goes right after JFIF APP0, if any
+		if !inserted && segment.Marker != MarkerAPP0 {
+			result = append(result, Segment{Marker: MarkerAPP1, Data: append(append([]byte(nil), exifHeader...), tiff...)})
+			inserted = true
+		}
+
+		result = append(result, segment)
+	}
+
+	if !inserted {
+		result = append(result, Segment{Marker: MarkerAPP1, Data: append(append([]byte(nil), exifHeader...), tiff...)})
+	}
+
+	return WriteSegments(result, scan)
+}
@@ -0,0 +1,65 @@
+package exif
+
+// Strip modes for original photo
+const (
+	StripNone      = "none"
+	StripSensitive = "sensitive" // GPS location and camera/device identifying tags
+	StripAll       = "all"       // everything except orientation
+)
+
+var gpsTagNames = map[uint16]string{
+	TagGPSLatitudeRef:  "GPSLatitudeRef",
+	TagGPSLatitude:     "GPSLatitude",
+	TagGPSLongitudeRef: "GPSLongitudeRef",
+	TagGPSLongitude:    "GPSLongitude",
+	TagGPSAltitude:     "GPSAltitude",
+}
+
+func gpsTagName(tag uint16) string {
+	if name, ok := gpsTagNames[tag]; ok {
+		return name
+	}
+
+	return "GPS" + tagHex(tag)
+}
+
+// Strip removes tags according to mode, returns names of removed tags
+func (e *Exif) Strip(mode string) []string {
+	removedNames := make([]string, 0)
+
+	var match func(tag uint16) bool
+
+	switch mode {
+	case StripSensitive:
+		match = IsDeviceTag
+	case StripAll:
+		match = func(tag uint16) bool { return tag != TagOrientation }
+	default:
+		return removedNames
+	}
+
+	var removed IFD
+
+	e.IFD0, removed = e.IFD0.Remove(match)
+	for _, entry := range removed {
+		removedNames = append(removedNames, TagName(entry.Tag))
+	}
+
+	e.ExifIFD, removed = e.ExifIFD.Remove(match)
+	for _, entry := range removed {
+		removedNames = append(removedNames, TagName(entry.Tag))
+	}
+
+	if mode == StripAll {
+		e.Interop = nil
+	}
+
+	// Location is removed in any strip mode
+	for _, entry := range e.GPSIFD {
+		removedNames = append(removedNames, gpsTagName(entry.Tag))
+	}
+
+	e.GPSIFD = nil
+
+	return removedNames
+}
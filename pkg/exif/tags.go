@@ -0,0 +1,104 @@
+package exif
+
+import "fmt"
+
+// IFD pointers
+const (
+	TagExifIFDPointer    uint16 = 0x8769
+	TagGPSIFDPointer     uint16 = 0x8825
+	TagInteropIFDPointer uint16 = 0xA005
+)
+
+// IFD0 tags
+const (
+	TagImageDescription uint16 = 0x010E
+	TagMake             uint16 = 0x010F
+	TagModel            uint16 = 0x0110
+	TagOrientation      uint16 = 0x0112
+	TagSoftware         uint16 = 0x0131
+	TagDateTime         uint16 = 0x0132
+	TagArtist           uint16 = 0x013B
+	TagHostComputer     uint16 = 0x013C
+	TagCopyright        uint16 = 0x8298
+)
+
+// Exif IFD tags
+const (
+	TagExposureTime      uint16 = 0x829A
+	TagFNumber           uint16 = 0x829D
+	TagISOSpeed          uint16 = 0x8827
+	TagDateTimeOriginal  uint16 = 0x9003
+	TagOffsetTimeOrig    uint16 = 0x9011
+	TagFocalLength       uint16 = 0x920A
+	TagMakerNote         uint16 = 0x927C
+	TagUserComment       uint16 = 0x9286
+	TagColorSpace        uint16 = 0xA001
+	TagImageUniqueID     uint16 = 0xA420
+	TagCameraOwnerName   uint16 = 0xA430
+	TagBodySerialNumber  uint16 = 0xA431
+	TagLensSpecification uint16 = 0xA432
+	TagLensMake          uint16 = 0xA433
+	TagLensModel         uint16 = 0xA434
+	TagLensSerialNumber  uint16 = 0xA435
+)
+
+// GPS IFD tags
+const (
+	TagGPSLatitudeRef  uint16 = 0x0001
+	TagGPSLatitude     uint16 = 0x0002
+	TagGPSLongitudeRef uint16 = 0x0003
+	TagGPSLongitude    uint16 = 0x0004
+	TagGPSAltitude     uint16 = 0x0006
+)
+
+var tagNames = map[uint16]string{
+	TagImageDescription:  "ImageDescription",
+	TagMake:              "Make",
+	TagModel:             "Model",
+	TagOrientation:       "Orientation",
+	TagSoftware:          "Software",
+	TagDateTime:          "DateTime",
+	TagArtist:            "Artist",
+	TagHostComputer:      "HostComputer",
+	TagCopyright:         "Copyright",
+	TagExposureTime:      "ExposureTime",
+	TagFNumber:           "FNumber",
+	TagISOSpeed:          "ISOSpeedRatings",
+	TagDateTimeOriginal:  "DateTimeOriginal",
+	TagOffsetTimeOrig:    "OffsetTimeOriginal",
+	TagFocalLength:       "FocalLength",
+	TagMakerNote:         "MakerNote",
+	TagUserComment:       "UserComment",
+	TagColorSpace:        "ColorSpace",
+	TagImageUniqueID:     "ImageUniqueID",
+	TagCameraOwnerName:   "CameraOwnerName",
+	TagBodySerialNumber:  "BodySerialNumber",
+	TagLensSpecification: "LensSpecification",
+	TagLensMake:          "LensMake",
+	TagLensModel:         "LensModel",
+	TagLensSerialNumber:  "LensSerialNumber",
+}
+
+// TagName returns readable name of IFD0/Exif IFD tag or its hex code
+func TagName(tag uint16) string {
+	if name, ok := tagNames[tag]; ok {
+		return name
+	}
+
+	return tagHex(tag)
+}
+
+func tagHex(tag uint16) string {
+	return fmt.Sprintf("0x%04X", tag)
+}
+
+// IsDeviceTag reports whether tag identifies camera, its owner or serial numbers
+func IsDeviceTag(tag uint16) bool {
+	switch tag {
+	case TagMake, TagModel, TagHostComputer, TagMakerNote, TagImageUniqueID, TagCameraOwnerName,
+		TagBodySerialNumber, TagLensMake, TagLensModel, TagLensSerialNumber, TagLensSpecification:
+		return true
+	default:
+		return false
+	}
+}
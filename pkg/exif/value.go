@@ -0,0 +1,195 @@
+package exif
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Orientation values
+const (
+	OrientationNormal = 1
+	OrientationMax    = 8
+)
+
+// Uint returns SHORT or LONG value of entry
+func (e *Exif) Uint(entry Entry) (uint32, bool) {
+	switch {
+	case entry.Type == TypeShort && len(entry.Value) >= 2:
+		return uint32(e.ByteOrder.Uint16(entry.Value)), true
+	case entry.Type == TypeLong && len(entry.Value) >= 4:
+		return e.ByteOrder.Uint32(entry.Value), true
+	default:
+		return 0, false
+	}
+}
+
+// Orientation returns EXIF orientation 1..8, OrientationNormal if tag is missing or invalid
+func (e *Exif) Orientation() int {
+	entry, ok := e.IFD0.Find(TagOrientation)
+	if !ok {
+		return OrientationNormal
+	}
+
+	value, ok := e.Uint(entry)
+	if !ok || value < OrientationNormal || value > OrientationMax {
+		return OrientationNormal
+	}
+
+	return int(value)
+}
+
+// ShortEntry creates SHORT entry in Exif byte order
+func (e *Exif) ShortEntry(tag, value uint16) Entry {
+	raw := make([]byte, 2)
+	e.ByteOrder.PutUint16(raw, value)
+
+	return Entry{Tag: tag, Type: TypeShort, Count: 1, Value: raw}
+}
+
+// ASCIIEntry creates NUL terminated ASCII entry
+func ASCIIEntry(tag uint16, value string) Entry {
+	raw := append([]byte(value), 0)
+
+	return Entry{Tag: tag, Type: TypeASCII, Count: uint32(len(raw)), Value: raw}
+}
+
+const (
+	exifDateTimeLayout = "2006:01:02 15:04:05"
+	exifOffsetLayout   = "-07:00"
+
+	gpsCoordinateParts = 3
+	minutesInDegree    = 60
+	secondsInDegree    = 3600
+)
+
+// String returns ASCII value without trailing NULs and spaces
+func (e *Exif) String(entry Entry) (string, bool) {
+	if entry.Type != TypeASCII && entry.Type != TypeUndefined {
+		return "", false
+	}
+
+	value := strings.TrimRight(string(entry.Value), "\x00 ")
+	value = strings.TrimSpace(value)
+
+	return value, value != ""
+}
+
+// Rationals returns RATIONAL or SRATIONAL values as floats, zero denominators are skipped
+func (e *Exif) Rationals(entry Entry) []float64 {
+	if entry.Type != TypeRational && entry.Type != TypeSRational {
+		return nil
+	}
+
+	values := make([]float64, 0, entry.Count)
+
+	for i := 0; i+8 <= len(entry.Value); i += 8 {
+		numerator := e.ByteOrder.Uint32(entry.Value[i:])
+		denominator := e.ByteOrder.Uint32(entry.Value[i+4:])
+
+		if denominator == 0 {
+			continue
+		}
+
+		if entry.Type == TypeSRational {
+			values = append(values, float64(int32(numerator))/float64(int32(denominator)))
+		} else {
+			values = append(values, float64(numerator)/float64(denominator))
+		}
+	}
+
+	return values
+}
+
+// RationalString returns first rational as "numerator/denominator", e.g. exposure time "1/125"
+func (e *Exif) RationalString(entry Entry) (string, bool) {
+	if entry.Type != TypeRational || len(entry.Value) < 8 {
+		return "", false
+	}
+
+	numerator := e.ByteOrder.Uint32(entry.Value)
+	denominator := e.ByteOrder.Uint32(entry.Value[4:])
+
+	if denominator == 0 {
+		return "", false
+	}
+
+	if numerator%denominator == 0 {
+		return strconv.FormatUint(uint64(numerator/denominator), 10), true
+	}
+
+	return fmt.Sprintf("%d/%d", numerator, denominator), true
+}
+
+// StringTag returns ASCII value of tag in ifd
+func (e *Exif) StringTag(ifd IFD, tag uint16) (string, bool) {
+	entry, ok := ifd.Find(tag)
+	if !ok {
+		return "", false
+	}
+
+	return e.String(entry)
+}
+
+// DateTimeOriginal returns capture time, camera local time is treated as UTC when offset isn't recorded
+func (e *Exif) DateTimeOriginal() (time.Time, bool) {
+	value, ok := e.StringTag(e.ExifIFD, TagDateTimeOriginal)
+	if !ok {
+		value, ok = e.StringTag(e.IFD0, TagDateTime)
+		if !ok {
+			return time.Time{}, false
+		}
+	}
+
+	location := time.UTC
+
+	if offset, ok := e.StringTag(e.ExifIFD, TagOffsetTimeOrig); ok {
+		if offsetTime, err := time.Parse(exifOffsetLayout, offset); err == nil {
+			_, seconds := offsetTime.Zone()
+			location = time.FixedZone(offset, seconds)
+		}
+	}
+
+	capturedAt, err := time.ParseInLocation(exifDateTimeLayout, value, location)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return capturedAt, true
+}
+
+// GPS returns latitude and longitude in decimal degrees
+func (e *Exif) GPS() (float64, float64, bool) {
+	latitude, ok := e.gpsCoordinate(TagGPSLatitude, TagGPSLatitudeRef, "S")
+	if !ok {
+		return 0, 0, false
+	}
+
+	longitude, ok := e.gpsCoordinate(TagGPSLongitude, TagGPSLongitudeRef, "W")
+	if !ok {
+		return 0, 0, false
+	}
+
+	return latitude, longitude, true
+}
+
+func (e *Exif) gpsCoordinate(tag, refTag uint16, negativeRef string) (float64, bool) {
+	entry, ok := e.GPSIFD.Find(tag)
+	if !ok {
+		return 0, false
+	}
+
+	parts := e.Rationals(entry)
+	if len(parts) != gpsCoordinateParts {
+		return 0, false
+	}
+
+	value := parts[0] + parts[1]/minutesInDegree + parts[2]/secondsInDegree
+
+	if ref, ok := e.StringTag(e.GPSIFD, refTag); ok && strings.EqualFold(ref, negativeRef) {
+		value = -value
+	}
+
+	return value, true
+}
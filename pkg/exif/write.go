@@ -0,0 +1,135 @@
+package exif
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Bytes serializes Exif to TIFF data, suitable for EXIF APP1 segment
+func (e *Exif) Bytes() []byte {
+	order := e.ByteOrder
+
+	pointerValue := make([]byte, 4)
+
+	ifd0 := append(IFD(nil), e.IFD0...)
+	exifIFD := append(IFD(nil), e.ExifIFD...)
+
+	if len(e.Interop) > 0 {
+		exifIFD = exifIFD.Set(Entry{Tag: TagInteropIFDPointer, Type: TypeLong, Count: 1, Value: pointerValue})
+	}
+
+	if len(exifIFD) > 0 {
+		ifd0 = ifd0.Set(Entry{Tag: TagExifIFDPointer, Type: TypeLong, Count: 1, Value: pointerValue})
+	}
+
+	if len(e.GPSIFD) > 0 {
+		ifd0 = ifd0.Set(Entry{Tag: TagGPSIFDPointer, Type: TypeLong, Count: 1, Value: pointerValue})
+	}
+
+	// Pointer values are inline, so sizes are known before offsets are filled
+	ifd0Offset := uint32(tiffHeaderSize)
+	exifOffset := ifd0Offset + ifdSize(ifd0)
+	interopOffset := exifOffset + ifdSize(exifIFD)
+	gpsOffset := interopOffset + ifdSize(e.Interop)
+
+	setPointer := func(ifd IFD, tag uint16, offset uint32) {
+		for i := range ifd {
+			if ifd[i].Tag == tag {
+				value := make([]byte, 4)
+				order.PutUint32(value, offset)
+				ifd[i].Value = value
+			}
+		}
+	}
+
+	setPointer(ifd0, TagExifIFDPointer, exifOffset)
+	setPointer(ifd0, TagGPSIFDPointer, gpsOffset)
+	setPointer(exifIFD, TagInteropIFDPointer, interopOffset)
+
+	buf := new(bytes.Buffer)
+
+	if order.Uint16([]byte{1, 0}) == 1 {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+
+	header := make([]byte, 6)
+	order.PutUint16(header[0:2], tiffMagic)
+	order.PutUint32(header[2:6], ifd0Offset)
+	buf.Write(header)
+
+	writeIFD(buf, e, ifd0, ifd0Offset)
+
+	if len(exifIFD) > 0 {
+		writeIFD(buf, e, exifIFD, exifOffset)
+	}
+
+	if len(e.Interop) > 0 {
+		writeIFD(buf, e, e.Interop, interopOffset)
+	}
+
+	if len(e.GPSIFD) > 0 {
+		writeIFD(buf, e, e.GPSIFD, gpsOffset)
+	}
+
+	return buf.Bytes()
+}
+
+func ifdSize(ifd IFD) uint32 {
+	if len(ifd) == 0 {
+		return 0
+	}
+
+	size := uint32(2 + len(ifd)*ifdEntrySize + 4)
+
+	for _, entry := range ifd {
+		if len(entry.Value) > 4 {
+			size += padded(uint32(len(entry.Value)))
+		}
+	}
+
+	return size
+}
+
+func padded(size uint32) uint32 {
+	return size + size%2
+}
+
+func writeIFD(buf *bytes.Buffer, e *Exif, ifd IFD, offset uint32) {
+	order := e.ByteOrder
+
+	// TIFF requires entries sorted by tag
+	sorted := append(IFD(nil), ifd...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Tag < sorted[j].Tag })
+
+	entries := make([]byte, 2+len(sorted)*ifdEntrySize+4)
+	order.PutUint16(entries[0:2], uint16(len(sorted)))
+
+	data := new(bytes.Buffer)
+	dataOffset := offset + uint32(len(entries))
+
+	for i, entry := range sorted {
+		raw := entries[2+i*ifdEntrySize:]
+		order.PutUint16(raw[0:2], entry.Tag)
+		order.PutUint16(raw[2:4], entry.Type)
+		order.PutUint32(raw[4:8], entry.Count)
+
+		if len(entry.Value) <= 4 {
+			copy(raw[8:12], entry.Value)
+
+			continue
+		}
+
+		order.PutUint32(raw[8:12], dataOffset+uint32(data.Len()))
+		data.Write(entry.Value)
+
+		if data.Len()%2 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	// next IFD offset stays 0, thumbnail IFD1 isn't written
+	buf.Write(entries)
+	buf.Write(data.Bytes())
+}
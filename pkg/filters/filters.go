@@ -0,0 +1,99 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+// Filter names
+const (
+	Grayscale    = "grayscale"
+	Sepia        = "sepia"
+	Vintage      = "vintage"
+	HighContrast = "high-contrast"
+)
+
+const (
+	DefaultStrength = 1.0
+
+	strengthSeparator = ":"
+)
+
+var (
+	ErrUnknownFilter   = errors.New("unknown filter")
+	ErrInvalidStrength = errors.New("filter strength must be 0-1")
+)
+
+// Filter is named image operation with strength 0-1, 0 leaves image as is
+type Filter struct {
+	Name     string
+	Strength float64
+}
+
+// Parse parses "name" or "name:strength" spec, e.g. "sepia:0.6"
+func Parse(spec string) (Filter, error) {
+	name, strength, hasStrength := strings.Cut(strings.TrimSpace(spec), strengthSeparator)
+
+	filter := Filter{Name: strings.ToLower(name), Strength: DefaultStrength}
+
+	if _, ok := operations[filter.Name]; !ok {
+		return filter, fmt.Errorf("%w: %s", ErrUnknownFilter, name)
+	}
+
+	if hasStrength {
+		value, err := strconv.ParseFloat(strength, 64)
+		if err != nil || value < 0 || value > 1 {
+			return filter, fmt.Errorf("%w: %s", ErrInvalidStrength, spec)
+		}
+
+		filter.Strength = value
+	}
+
+	return filter, nil
+}
+
+// ParseAll parses list of filter specs, filters are applied in listed order
+func ParseAll(specs []string) ([]Filter, error) {
+	filters := make([]Filter, 0, len(specs))
+
+	for _, spec := range specs {
+		filter, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// String returns canonical spec of filter, Parse(f.String()) == f
+func (f Filter) String() string {
+	return f.Name + strengthSeparator + strconv.FormatFloat(f.Strength, 'f', -1, 64)
+}
+
+// Apply returns copy of image with filters applied in order, image itself is not modified
+func Apply(img image.Image, filters []Filter) image.Image {
+	if len(filters) == 0 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	result := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+
+	for _, filter := range filters {
+		if filter.Strength == 0 {
+			continue
+		}
+
+		operations[filter.Name](result, filter.Strength)
+	}
+
+	return result
+}
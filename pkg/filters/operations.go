@@ -0,0 +1,128 @@
+package filters
+
+import (
+	"image"
+	"math"
+)
+
+type operation func(img *image.NRGBA, strength float64)
+
+var operations = map[string]operation{
+	Grayscale:    grayscale,
+	Sepia:        sepia,
+	Vintage:      vintage,
+	HighContrast: highContrast,
+}
+
+// colorMatrix is 3x3 RGB transform, rows give output channels
+type colorMatrix [3][3]float64
+
+var (
+	grayscaleMatrix = colorMatrix{
+		{0.299, 0.587, 0.114},
+		{0.299, 0.587, 0.114},
+		{0.299, 0.587, 0.114},
+	}
+
+	sepiaMatrix = colorMatrix{
+		{0.393, 0.769, 0.189},
+		{0.349, 0.686, 0.168},
+		{0.272, 0.534, 0.131},
+	}
+)
+
+const (
+	maxContrastGain = 1.0  // high-contrast at full strength doubles contrast
+	vintageFade     = 0.25 // lifted blacks and lowered whites
+	vintageVignette = 0.4  // corners darkening at full strength
+	vintageWarmth   = 12   // red boost and blue cut
+)
+
+func grayscale(img *image.NRGBA, strength float64) {
+	applyMatrix(img, grayscaleMatrix, strength)
+}
+
+func sepia(img *image.NRGBA, strength float64) {
+	applyMatrix(img, sepiaMatrix, strength)
+}
+
+func highContrast(img *image.NRGBA, strength float64) {
+	applyContrast(img, 1+maxContrastGain*strength)
+}
+
+// vintage is faded warm sepia with vignette
+func vintage(img *image.NRGBA, strength float64) {
+	applyMatrix(img, sepiaMatrix, strength*0.6)
+	applyContrast(img, 1-vintageFade*strength)
+	applyWarmth(img, vintageWarmth*strength)
+	applyVignette(img, vintageVignette*strength)
+}
+
+// applyMatrix blends every pixel with its matrix transform by strength
+func applyMatrix(img *image.NRGBA, matrix colorMatrix, strength float64) {
+	forEachPixel(img, func(_, _ int, pixel []uint8) {
+		r, g, b := float64(pixel[0]), float64(pixel[1]), float64(pixel[2])
+
+		for channel := 0; channel < 3; channel++ {
+			row := matrix[channel]
+			transformed := row[0]*r + row[1]*g + row[2]*b
+			pixel[channel] = clamp(float64(pixel[channel]) + (transformed-float64(pixel[channel]))*strength)
+		}
+	})
+}
+
+// applyContrast scales distance of every channel from middle gray by factor
+func applyContrast(img *image.NRGBA, factor float64) {
+	var lookup [256]uint8
+	for value := range lookup {
+		lookup[value] = clamp((float64(value)-128)*factor + 128)
+	}
+
+	forEachPixel(img, func(_, _ int, pixel []uint8) {
+		pixel[0], pixel[1], pixel[2] = lookup[pixel[0]], lookup[pixel[1]], lookup[pixel[2]]
+	})
+}
+
+func applyWarmth(img *image.NRGBA, amount float64) {
+	forEachPixel(img, func(_, _ int, pixel []uint8) {
+		pixel[0] = clamp(float64(pixel[0]) + amount)
+		pixel[2] = clamp(float64(pixel[2]) - amount)
+	})
+}
+
+// applyVignette darkens pixels by squared distance from center, corners lose amount of brightness
+func applyVignette(img *image.NRGBA, amount float64) {
+	bounds := img.Bounds()
+	centerX, centerY := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+	maxDistance := centerX*centerX + centerY*centerY
+
+	if maxDistance == 0 {
+		return
+	}
+
+	forEachPixel(img, func(x, y int, pixel []uint8) {
+		dx, dy := float64(x)-centerX, float64(y)-centerY
+		brightness := 1 - amount*(dx*dx+dy*dy)/maxDistance
+
+		pixel[0] = clamp(float64(pixel[0]) * brightness)
+		pixel[1] = clamp(float64(pixel[1]) * brightness)
+		pixel[2] = clamp(float64(pixel[2]) * brightness)
+	})
+}
+
+// forEachPixel calls fn with RGBA bytes of every pixel, x and y are relative to image bounds
+func forEachPixel(img *image.NRGBA, fn func(x, y int, pixel []uint8)) {
+	bounds := img.Bounds()
+
+	for y := 0; y < bounds.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+bounds.Dx()*4]
+
+		for x := 0; x < bounds.Dx(); x++ {
+			fn(x, y, row[x*4:x*4+4])
+		}
+	}
+}
+
+func clamp(value float64) uint8 {
+	return uint8(math.Max(0, math.Min(math.MaxUint8, math.Round(value))))
+}
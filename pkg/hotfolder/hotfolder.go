@@ -0,0 +1,275 @@
+package hotfolder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+
+	"test-task-photo-booth/pkg/clients"
+	"test-task-photo-booth/pkg/utils"
+	"test-task-photo-booth/src/entities"
+	"test-task-photo-booth/src/entities/customErrors"
+	"test-task-photo-booth/src/entities/dtos"
+)
+
+// Viper config keys
+const (
+	configHotFolderDirs   = "hotFolder.dirs"
+	configHotFolderSettle = "hotFolder.settleMs"
+)
+
+const (
+	DoneDirName     = "done"
+	FailedDirName   = "failed"
+	JournalFileName = ".photo-booth-journal"
+
+	defaultSettle = 2000
+
+	dirPermission = 0o755
+)
+
+// HotFolder watches directories, where tethered cameras write photos, and publishes new photos in queue.
+// Published photos are moved to done subfolder, photos which can't be processed to failed one
+type HotFolder struct {
+	dirs     []string
+	settle   time.Duration
+	queue    clients.PhotoQueue
+	journals map[string]*Journal
+	log      *zerolog.Logger
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+}
+
+func NewHotFolder(queue clients.PhotoQueue, log *zerolog.Logger) (*HotFolder, error) {
+	viper.SetDefault(configHotFolderSettle, defaultSettle)
+
+	dirs := viper.GetStringSlice(configHotFolderDirs)
+	if len(dirs) == 0 {
+		return nil, customErrors.ErrNoHotFolderDirs
+	}
+
+	// Paths of scanned and watched files are clean, so journals are looked up by clean dir of file
+	for i, dir := range dirs {
+		dirs[i] = filepath.Clean(dir)
+	}
+
+	h := &HotFolder{
+		dirs:     dirs,
+		settle:   time.Duration(viper.GetInt(configHotFolderSettle)) * time.Millisecond,
+		queue:    queue,
+		journals: make(map[string]*Journal, len(dirs)),
+		log:      log,
+		pending:  make(map[string]*time.Timer),
+	}
+
+	for _, dir := range dirs {
+		for _, sub := range []string{DoneDirName, FailedDirName} {
+			if err := os.MkdirAll(filepath.Join(dir, sub), dirPermission); err != nil {
+				return nil, fmt.Errorf("os.MkdirAll() failed: %w", err)
+			}
+		}
+
+		journal, err := OpenJournal(filepath.Join(dir, JournalFileName))
+		if err != nil {
+			return nil, fmt.Errorf("OpenJournal() failed: %w", err)
+		}
+
+		h.journals[dir] = journal
+	}
+
+	return h, nil
+}
+
+// Watch blocks until ctx is done. Files left in directories from previous run are processed first
+func (h *HotFolder) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("fsnotify.NewWatcher() failed: %w", err)
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			h.log.Error().Err(err).Msg("watcher.Close() failed")
+		}
+	}()
+
+	for _, dir := range h.dirs {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watcher.Add() failed: %w", err)
+		}
+
+		if err := h.scan(dir); err != nil {
+			return fmt.Errorf("h.scan() failed: %w", err)
+		}
+
+		h.log.Info().Msgf("watching hot folder: %s", dir)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+				h.schedule(event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			h.log.Error().Err(err).Msg("hot folder watcher error")
+		}
+	}
+}
+
+func (h *HotFolder) scan(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("os.ReadDir() failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		h.schedule(filepath.Join(dir, entry.Name()))
+	}
+
+	return nil
+}
+
+// schedule (re)starts settle timer of file, so file is processed only after writes to it stopped
+func (h *HotFolder) schedule(path string) {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if timer, ok := h.pending[path]; ok {
+		timer.Reset(h.settle)
+
+		return
+	}
+
+	h.pending[path] = time.AfterFunc(h.settle, func() {
+		h.mu.Lock()
+		delete(h.pending, path)
+		h.mu.Unlock()
+
+		h.processWhenWritten(path)
+	})
+}
+
+func (h *HotFolder) processWhenWritten(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			h.log.Error().Err(err).Msgf("os.Stat() failed for %s", path)
+		}
+
+		return
+	}
+
+	if info.IsDir() {
+		return
+	}
+
+	// Camera may keep writing without fs events, e.g. on network shares
+	time.Sleep(h.settle)
+
+	settledInfo, err := os.Stat(path)
+	if err != nil {
+		h.log.Error().Err(err).Msgf("os.Stat() failed for %s", path)
+
+		return
+	}
+
+	if settledInfo.Size() != info.Size() || !settledInfo.ModTime().Equal(info.ModTime()) {
+		h.schedule(path)
+
+		return
+	}
+
+	h.process(path)
+}
+
+func (h *HotFolder) process(path string) {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+
+	data, err := utils.GetFileBytes(path)
+	if err != nil {
+		h.log.Error().Err(err).Msgf("utils.GetFileBytes() failed for %s", name)
+
+		return
+	}
+
+	mimeType := utils.GetB64MimeType(data)
+	if !utils.IsSupportedPhotoMimeType(mimeType) {
+		h.log.Warn().Msgf("hot folder file %s skipped: %s: %s", name, customErrors.ErrUnsupportedPhotoType, mimeType)
+		h.move(path, FailedDirName)
+
+		return
+	}
+
+	hashSum := sha256.Sum256(data)
+	hash := hex.EncodeToString(hashSum[:])
+
+	journal := h.journals[dir]
+	if journal.Contains(hash) {
+		h.log.Info().Msgf("hot folder file %s already imported", name)
+		h.move(path, DoneDirName)
+
+		return
+	}
+
+	message := &dtos.PhotoMessage{
+		Type: entities.PhotoMessageUpload,
+		Data: base64.StdEncoding.EncodeToString(data),
+	}
+
+	if err := h.queue.Publish(message); err != nil {
+		// Queue might be temporary unavailable, file stays in place and is retried
+		h.log.Error().Err(err).Msgf("queue.Publish() failed for %s, retrying", name)
+		h.schedule(path)
+
+		return
+	}
+
+	if err := journal.Add(hash, name); err != nil {
+		h.log.Error().Err(err).Msgf("journal.Add() failed for %s", name)
+	}
+
+	h.move(path, DoneDirName)
+
+	h.log.Info().Msgf("hot folder file %s published", name)
+}
+
+func (h *HotFolder) move(path, subDir string) {
+	target := filepath.Join(filepath.Dir(path), subDir, filepath.Base(path))
+	if utils.IsFileExists(target) {
+		ext := filepath.Ext(target)
+		target = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(target, ext), time.Now().UnixNano(), ext)
+	}
+
+	if err := os.Rename(path, target); err != nil {
+		h.log.Error().Err(err).Msgf("os.Rename() failed for %s", filepath.Base(path))
+	}
+}
@@ -0,0 +1,95 @@
+package hotfolder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"test-task-photo-booth/pkg/logger"
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+const journalFilePermission = 0o600
+
+// Journal keeps sha256 of already published files, so restarts don't import same photo twice.
+// One record per line: "<sha256> <RFC3339 time> <file name>"
+type Journal struct {
+	path   string
+	mu     sync.Mutex
+	hashes map[string]struct{}
+}
+
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{
+		path:   path,
+		hashes: make(map[string]struct{}),
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return j, nil
+		}
+
+		return nil, fmt.Errorf("os.Open() failed: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Log.Error().Err(customErrors.ErrorOsCloseFailed).Err(err).Send()
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(scanner.Text(), " ")
+		if hash != "" {
+			j.hashes[hash] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner.Scan() failed: %w", err)
+	}
+
+	return j, nil
+}
+
+func (j *Journal) Contains(hash string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, ok := j.hashes[hash]
+
+	return ok
+}
+
+// Add appends record and syncs it to disk before returning
+func (j *Journal) Add(hash, fileName string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, journalFilePermission)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile() failed: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Log.Error().Err(customErrors.ErrorOsCloseFailed).Err(err).Send()
+		}
+	}()
+
+	if _, err := fmt.Fprintf(file, "%s %s %s\n", hash, time.Now().UTC().Format(time.RFC3339), fileName); err != nil {
+		return fmt.Errorf("fmt.Fprintf() failed: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("file.Sync() failed: %w", err)
+	}
+
+	j.hashes[hash] = struct{}{}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package icc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"test-task-photo-booth/pkg/exif"
+)
+
+const (
+	maxChunkSize   = exif.MaxSegmentSize - iccChunkHeaderSize
+	maxProfileSize = 16 << 20 // 16MB
+
+	iccChunkHeaderSize = len(iccSignature) + 2 // sequence number and chunk count
+	iccSignature       = "ICC_PROFILE\x00"
+)
+
+var (
+	iccHeader = []byte(iccSignature)
+	pngHeader = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// ExtractJPEG reassembles ICC profile of JPEG, profile is split into numbered APP2 chunks
+func ExtractJPEG(data []byte) ([]byte, error) {
+	segments, _, err := exif.ReadSegments(data)
+	if err != nil {
+		return nil, fmt.Errorf("exif.ReadSegments() failed: %w", err)
+	}
+
+	type chunk struct {
+		sequence int
+		data     []byte
+	}
+
+	chunks := make([]chunk, 0)
+	count := 0
+
+	for _, segment := range segments {
+		if !isICCSegment(segment) {
+			continue
+		}
+
+		if len(segment.Data) < iccChunkHeaderSize {
+			return nil, fmt.Errorf("%w: truncated chunk", ErrInvalidProfile)
+		}
+
+		chunks = append(chunks, chunk{
+			sequence: int(segment.Data[len(iccHeader)]),
+			data:     segment.Data[iccChunkHeaderSize:],
+		})
+		count = int(segment.Data[len(iccHeader)+1])
+	}
+
+	if len(chunks) == 0 {
+		return nil, ErrNoProfile
+	}
+
+	if len(chunks) != count {
+		return nil, fmt.Errorf("%w: %d of %d chunks", ErrInvalidProfile, len(chunks), count)
+	}
+
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].sequence < chunks[j].sequence })
+
+	profile := make([]byte, 0)
+	for i, c := range chunks {
+		if c.sequence != i+1 {
+			return nil, fmt.Errorf("%w: unexpected chunk %d", ErrInvalidProfile, c.sequence)
+		}
+
+		profile = append(profile, c.data...)
+	}
+
+	return profile, nil
+}
+
+// EmbedJPEG replaces ICC profile of JPEG with profile, chunks go right after JFIF APP0 and EXIF APP1
+func EmbedJPEG(data, profile []byte) ([]byte, error) {
+	segments, scan, err := exif.ReadSegments(data)
+	if err != nil {
+		return nil, fmt.Errorf("exif.ReadSegments() failed: %w", err)
+	}
+
+	count := (len(profile) + maxChunkSize - 1) / maxChunkSize
+	if count > 255 {
+		return nil, fmt.Errorf("%w: profile too large", ErrInvalidProfile)
+	}
+
+	chunks := make([]exif.Segment, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := profile[i*maxChunkSize : min((i+1)*maxChunkSize, len(profile))]
+
+		chunkData := make([]byte, 0, iccChunkHeaderSize+len(chunk))
+		chunkData = append(chunkData, iccHeader...)
+		chunkData = append(chunkData, byte(i+1), byte(count))
+		chunkData = append(chunkData, chunk...)
+
+		chunks = append(chunks, exif.Segment{Marker: exif.MarkerAPP2, Data: chunkData})
+	}
+
+	result := make([]exif.Segment, 0, len(segments)+count)
+	inserted := false
+
+	for _, segment := range segments {
+		if isICCSegment(segment) {
+			continue
+		}
+
+		if !inserted && segment.Marker != exif.MarkerAPP0 && segment.Marker != exif.MarkerAPP1 {
+			result = append(result, chunks...)
+			inserted = true
+		}
+
+		result = append(result, segment)
+	}
+
+	if !inserted {
+		result = append(result, chunks...)
+	}
+
+	jpeg, err := exif.WriteSegments(result, scan)
+	if err != nil {
+		return nil, fmt.Errorf("exif.WriteSegments() failed: %w", err)
+	}
+
+	return jpeg, nil
+}
+
+// ExtractPNG returns decompressed ICC profile of PNG iCCP chunk
+func ExtractPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngHeader) {
+		return nil, fmt.Errorf("%w: not png", ErrInvalidProfile)
+	}
+
+	pos := len(pngHeader)
+
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+
+		if length < 0 || pos+12+length > len(data) {
+			return nil, fmt.Errorf("%w: truncated png chunk", ErrInvalidProfile)
+		}
+
+		switch chunkType {
+		case "iCCP":
+			return decompressPNGProfile(data[pos+8 : pos+8+length])
+		case "IDAT", "IEND": // iCCP must precede image data
+			return nil, ErrNoProfile
+		}
+
+		pos += 12 + length
+	}
+
+	return nil, ErrNoProfile
+}
+
+// decompressPNGProfile decompresses iCCP chunk, profile name and compression method precede zlib stream
+func decompressPNGProfile(chunk []byte) ([]byte, error) {
+	nameEnd := bytes.IndexByte(chunk, 0)
+	if nameEnd < 0 || nameEnd+2 > len(chunk) {
+		return nil, fmt.Errorf("%w: malformed iCCP chunk", ErrInvalidProfile)
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(chunk[nameEnd+2:]))
+	if err != nil {
+		return nil, fmt.Errorf("zlib.NewReader() failed: %w", err)
+	}
+	defer reader.Close()
+
+	profile, err := io.ReadAll(io.LimitReader(reader, maxProfileSize))
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll() failed: %w", err)
+	}
+
+	return profile, nil
+}
+
+func isICCSegment(segment exif.Segment) bool {
+	return segment.Marker == exif.MarkerAPP2 && bytes.HasPrefix(segment.Data, iccHeader)
+}
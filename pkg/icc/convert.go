@@ -0,0 +1,99 @@
+package icc
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+const (
+	inputLevels  = 256
+	outputLevels = 4096
+)
+
+// xyzD50ToSRGB converts D50 adapted XYZ to linear sRGB, inverse of srgbD50
+var xyzD50ToSRGB = matrix{
+	{3.1338561, -1.6168667, -0.4906146},
+	{-0.9787684, 1.9161415, 0.0334540},
+	{0.0719453, -0.2289914, 1.4052427},
+}
+
+// ToSRGB converts image pixels from profile color space to sRGB. Image is returned as is
+// when profile is sRGB already or can't be converted
+func (p *Profile) ToSRGB(img image.Image) image.Image {
+	if !p.IsConvertible() || p.IsSRGB() {
+		return img
+	}
+
+	transform := xyzD50ToSRGB.multiply(*p.primaries)
+
+	var input [3][inputLevels]float64
+	for channel := range input {
+		for level := range input[channel] {
+			input[channel][level] = p.curves[channel].apply(float64(level) / (inputLevels - 1))
+		}
+	}
+
+	var output [outputLevels]uint8
+	for level := range output {
+		output[level] = uint8(math.Round(linearToSRGB(float64(level)/(outputLevels-1)) * 255))
+	}
+
+	bounds := img.Bounds()
+	converted := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(converted, converted.Bounds(), img, bounds.Min, draw.Src)
+
+	pix := converted.Pix
+	for i := 0; i < len(pix); i += 4 {
+		alpha := pix[i+3]
+		if alpha == 0 {
+			continue
+		}
+
+		var rgb [3]uint8
+		for channel := range rgb {
+			rgb[channel] = unpremultiply(pix[i+channel], alpha)
+		}
+
+		r, g, b := input[0][rgb[0]], input[1][rgb[1]], input[2][rgb[2]]
+
+		for channel := range rgb {
+			linear := transform[channel][0]*r + transform[channel][1]*g + transform[channel][2]*b
+			level := int(math.Round(min(max(linear, 0), 1) * (outputLevels - 1)))
+
+			pix[i+channel] = premultiply(output[level], alpha)
+		}
+	}
+
+	return converted
+}
+
+func (m matrix) multiply(other matrix) matrix {
+	var result matrix
+
+	for row := range result {
+		for column := range result[row] {
+			for i := range m[row] {
+				result[row][column] += m[row][i] * other[i][column]
+			}
+		}
+	}
+
+	return result
+}
+
+func unpremultiply(value, alpha uint8) uint8 {
+	if alpha == 0xFF {
+		return value
+	}
+
+	return uint8(min(uint32(value)*0xFF/uint32(alpha), 0xFF))
+}
+
+func premultiply(value, alpha uint8) uint8 {
+	if alpha == 0xFF {
+		return value
+	}
+
+	return uint8(uint32(value) * uint32(alpha) / 0xFF)
+}
@@ -0,0 +1,110 @@
+package icc
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Parametric curve function types, number of parameters is given by index
+var parametricParams = []int{1, 3, 4, 5, 7}
+
+// curve maps encoded channel value 0-1 to linear light 0-1
+type curve struct {
+	gamma      float64   // used when table and params are empty
+	table      []float64 // sampled curve
+	params     []float64 // parametric curve g, a, b, c, d, e, f
+	parametric bool
+}
+
+// readCurve reads curveType or parametricCurveType
+func readCurve(tag []byte) (curve, bool) {
+	if len(tag) < 12 {
+		return curve{}, false
+	}
+
+	switch string(tag[:4]) {
+	case "curv":
+		count := int(binary.BigEndian.Uint32(tag[8:]))
+		if 12+count*2 > len(tag) {
+			return curve{}, false
+		}
+
+		switch count {
+		case 0:
+			return curve{gamma: 1}, true
+		case 1:
+			return curve{gamma: float64(binary.BigEndian.Uint16(tag[12:])) / u8Fixed8Scale}, true
+		default:
+			table := make([]float64, count)
+			for i := range table {
+				table[i] = float64(binary.BigEndian.Uint16(tag[12+i*2:])) / curveTableMax
+			}
+
+			return curve{table: table}, true
+		}
+	case "para":
+		function := int(binary.BigEndian.Uint16(tag[8:]))
+		if function >= len(parametricParams) || 12+parametricParams[function]*4 > len(tag) {
+			return curve{}, false
+		}
+
+		// Missing parameters are filled so every function type is evaluated as type 4
+		params := []float64{1, 1, 0, 0, 0, 0, 0}
+		for i := 0; i < parametricParams[function]; i++ {
+			params[i] = s15Fixed16(tag[12+i*4:])
+		}
+
+		switch function {
+		case 1:
+			params[4] = -params[2] / params[1] // d = -b/a
+		case 2:
+			params[4] = -params[2] / params[1]
+			params[5] = params[3] // e = c below and above threshold
+			params[6] = params[3]
+			params[3] = 0
+		}
+
+		return curve{params: params, parametric: true}, true
+	default:
+		return curve{}, false
+	}
+}
+
+func (c curve) apply(x float64) float64 {
+	switch {
+	case c.parametric:
+		g, a, b, cc, d, e, f := c.params[0], c.params[1], c.params[2], c.params[3], c.params[4], c.params[5], c.params[6]
+		if x >= d {
+			return math.Pow(math.Max(a*x+b, 0), g) + e
+		}
+
+		return cc*x + f
+	case len(c.table) > 0:
+		position := x * float64(len(c.table)-1)
+		i := int(position)
+
+		if i >= len(c.table)-1 {
+			return c.table[len(c.table)-1]
+		}
+
+		return c.table[i] + (c.table[i+1]-c.table[i])*(position-float64(i))
+	default:
+		return math.Pow(x, c.gamma)
+	}
+}
+
+func srgbToLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(x float64) float64 {
+	if x <= 0.0031308 {
+		return x * 12.92
+	}
+
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
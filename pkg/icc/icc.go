@@ -0,0 +1,227 @@
+package icc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	headerSize       = 128
+	tagEntrySize     = 12
+	maxTagCount      = 1024
+	s15Fixed16Scale  = 65536
+	u8Fixed8Scale    = 256
+	curveTableMax    = 65535
+	srgbMatrixMaxErr = 0.01
+	srgbCurveMaxErr  = 0.02
+)
+
+// Color spaces of profile header
+const (
+	ColorSpaceRGB  = "RGB"
+	ColorSpaceGray = "GRAY"
+	ColorSpaceCMYK = "CMYK"
+)
+
+// Tag signatures
+const (
+	tagDescription = "desc"
+	tagRedXYZ      = "rXYZ"
+	tagGreenXYZ    = "gXYZ"
+	tagBlueXYZ     = "bXYZ"
+	tagRedTRC      = "rTRC"
+	tagGreenTRC    = "gTRC"
+	tagBlueTRC     = "bTRC"
+)
+
+var (
+	ErrNoProfile      = errors.New("no icc profile")
+	ErrInvalidProfile = errors.New("invalid icc profile")
+)
+
+// srgbD50 is sRGB primaries matrix adapted to D50 profile connection space, columns are red, green and blue XYZ
+var srgbD50 = matrix{
+	{0.4360747, 0.3850649, 0.1430804},
+	{0.2225045, 0.7168786, 0.0606169},
+	{0.0139322, 0.0971045, 0.7141733},
+}
+
+// matrix is 3x3 transform of column vectors
+type matrix [3][3]float64
+
+// Profile is parsed ICC profile. RGB profiles of matrix/TRC model can be converted to sRGB,
+// LUT based profiles are recognised by description only
+type Profile struct {
+	Data        []byte
+	ColorSpace  string
+	Description string
+
+	primaries *matrix  // red, green and blue colorants in D50 XYZ, nil unless matrix/TRC profile
+	curves    [3]curve // red, green and blue tone curves to linear light
+}
+
+// Parse parses ICC profile header, description and matrix/TRC tags
+func Parse(data []byte) (*Profile, error) {
+	if len(data) < headerSize+4 {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidProfile)
+	}
+
+	profile := &Profile{
+		Data:       data,
+		ColorSpace: strings.TrimSpace(string(data[16:20])),
+	}
+
+	tags, err := readTags(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag, ok := tags[tagDescription]; ok {
+		profile.Description = readDescription(tag)
+	}
+
+	if profile.ColorSpace != ColorSpaceRGB {
+		return profile, nil
+	}
+
+	var primaries matrix
+
+	for column, signature := range []string{tagRedXYZ, tagGreenXYZ, tagBlueXYZ} {
+		xyz, ok := readXYZ(tags[signature])
+		if !ok {
+			return profile, nil
+		}
+
+		for row := range xyz {
+			primaries[row][column] = xyz[row]
+		}
+	}
+
+	for channel, signature := range []string{tagRedTRC, tagGreenTRC, tagBlueTRC} {
+		toneCurve, ok := readCurve(tags[signature])
+		if !ok {
+			return profile, nil
+		}
+
+		profile.curves[channel] = toneCurve
+	}
+
+	profile.primaries = &primaries
+
+	return profile, nil
+}
+
+// IsConvertible reports whether profile pixels can be converted to sRGB
+func (p *Profile) IsConvertible() bool {
+	return p.primaries != nil
+}
+
+// IsSRGB reports whether profile is sRGB or close enough to it for conversion to be skipped
+func (p *Profile) IsSRGB() bool {
+	if p.primaries == nil {
+		return strings.Contains(p.Description, "sRGB")
+	}
+
+	for row := range srgbD50 {
+		for column := range srgbD50[row] {
+			if math.Abs(p.primaries[row][column]-srgbD50[row][column]) > srgbMatrixMaxErr {
+				return false
+			}
+		}
+	}
+
+	for _, toneCurve := range p.curves {
+		for _, x := range []float64{0.1, 0.25, 0.5, 0.75, 0.9} {
+			if math.Abs(toneCurve.apply(x)-srgbToLinear(x)) > srgbCurveMaxErr {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func readTags(data []byte) (map[string][]byte, error) {
+	count := binary.BigEndian.Uint32(data[headerSize:])
+	if count > maxTagCount || headerSize+4+int(count)*tagEntrySize > len(data) {
+		return nil, fmt.Errorf("%w: invalid tag table", ErrInvalidProfile)
+	}
+
+	tags := make(map[string][]byte, count)
+
+	for i := 0; i < int(count); i++ {
+		entry := data[headerSize+4+i*tagEntrySize:]
+		offset := binary.BigEndian.Uint32(entry[4:])
+		size := binary.BigEndian.Uint32(entry[8:])
+
+		if uint64(offset)+uint64(size) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: tag %q out of bounds", ErrInvalidProfile, entry[:4])
+		}
+
+		tags[string(entry[:4])] = data[offset : offset+size]
+	}
+
+	return tags, nil
+}
+
+// readDescription reads v2 textDescriptionType or v4 multiLocalizedUnicodeType, first record of the latter
+func readDescription(tag []byte) string {
+	if len(tag) < 12 {
+		return ""
+	}
+
+	switch string(tag[:4]) {
+	case "desc":
+		length := binary.BigEndian.Uint32(tag[8:])
+		if uint64(12)+uint64(length) > uint64(len(tag)) {
+			return ""
+		}
+
+		return strings.TrimSpace(string(bytes.TrimRight(tag[12:12+length], "\x00")))
+	case "mluc":
+		if len(tag) < 28 || binary.BigEndian.Uint32(tag[8:]) == 0 {
+			return ""
+		}
+
+		length := binary.BigEndian.Uint32(tag[20:])
+		offset := binary.BigEndian.Uint32(tag[24:])
+
+		if uint64(offset)+uint64(length) > uint64(len(tag)) {
+			return ""
+		}
+
+		text := tag[offset : offset+length]
+		units := make([]uint16, len(text)/2)
+
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(text[i*2:])
+		}
+
+		return strings.TrimSpace(strings.TrimRight(string(utf16.Decode(units)), "\x00"))
+	default:
+		return ""
+	}
+}
+
+func readXYZ(tag []byte) ([3]float64, bool) {
+	var xyz [3]float64
+
+	if len(tag) < 20 || string(tag[:4]) != "XYZ " {
+		return xyz, false
+	}
+
+	for i := range xyz {
+		xyz[i] = s15Fixed16(tag[8+i*4:])
+	}
+
+	return xyz, true
+}
+
+func s15Fixed16(data []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / s15Fixed16Scale
+}
@@ -0,0 +1,459 @@
+package icc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"unicode/utf16"
+
+	"test-task-photo-booth/pkg/exif"
+)
+
+type testTag struct {
+	signature string
+	data      []byte
+}
+
+// adobeD50 is Adobe RGB (1998) primaries matrix adapted to D50
+var adobeD50 = matrix{
+	{0.60974, 0.20528, 0.14919},
+	{0.31111, 0.62567, 0.06322},
+	{0.01947, 0.06087, 0.74457},
+}
+
+func s15Fixed16Bytes(value float64) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(int32(value*s15Fixed16Scale)))
+}
+
+func descTag(description string) []byte {
+	tag := append([]byte("desc\x00\x00\x00\x00"), binary.BigEndian.AppendUint32(nil, uint32(len(description)+1))...)
+
+	return append(tag, append([]byte(description), 0)...)
+}
+
+func mlucTag(description string) []byte {
+	units := utf16.Encode([]rune(description))
+
+	tag := []byte("mluc\x00\x00\x00\x00")
+	tag = binary.BigEndian.AppendUint32(tag, 1)  // record count
+	tag = binary.BigEndian.AppendUint32(tag, 12) // record size
+	tag = append(tag, "enUS"...)
+	tag = binary.BigEndian.AppendUint32(tag, uint32(len(units)*2))
+	tag = binary.BigEndian.AppendUint32(tag, 28)
+
+	for _, unit := range units {
+		tag = binary.BigEndian.AppendUint16(tag, unit)
+	}
+
+	return tag
+}
+
+func gammaTag(gamma float64) []byte {
+	return binary.BigEndian.AppendUint16([]byte("curv\x00\x00\x00\x00\x00\x00\x00\x01"), uint16(gamma*u8Fixed8Scale))
+}
+
+// srgbTag is sRGB tone curve as parametric function type 3
+func srgbTag() []byte {
+	tag := binary.BigEndian.AppendUint16([]byte("para\x00\x00\x00\x00"), 3)
+	tag = append(tag, 0, 0)
+
+	for _, param := range []float64{2.4, 1 / 1.055, 0.055 / 1.055, 1 / 12.92, 0.04045} {
+		tag = append(tag, s15Fixed16Bytes(param)...)
+	}
+
+	return tag
+}
+
+func matrixTRCTags(description []byte, primaries matrix, trc []byte) []testTag {
+	tags := []testTag{{signature: tagDescription, data: description}}
+
+	for column, signature := range []string{tagRedXYZ, tagGreenXYZ, tagBlueXYZ} {
+		xyz := []byte("XYZ \x00\x00\x00\x00")
+		for row := range primaries {
+			xyz = append(xyz, s15Fixed16Bytes(primaries[row][column])...)
+		}
+
+		tags = append(tags, testTag{signature: signature, data: xyz})
+	}
+
+	for _, signature := range []string{tagRedTRC, tagGreenTRC, tagBlueTRC} {
+		tags = append(tags, testTag{signature: signature, data: trc})
+	}
+
+	return tags
+}
+
+func buildProfile(colorSpace string, tags []testTag) []byte {
+	header := make([]byte, headerSize)
+	copy(header[16:], colorSpace+"    ")
+	copy(header[36:], "acsp")
+
+	table := binary.BigEndian.AppendUint32(nil, uint32(len(tags)))
+	body := new(bytes.Buffer)
+	dataOffset := headerSize + 4 + len(tags)*tagEntrySize
+
+	for _, tag := range tags {
+		table = append(table, tag.signature...)
+		table = binary.BigEndian.AppendUint32(table, uint32(dataOffset+body.Len()))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(tag.data)))
+
+		body.Write(tag.data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	profile := append(append(header, table...), body.Bytes()...)
+	binary.BigEndian.PutUint32(profile, uint32(len(profile)))
+
+	return profile
+}
+
+func srgbProfile() []byte {
+	return buildProfile(ColorSpaceRGB, matrixTRCTags(descTag("sRGB IEC61966-2.1"), srgbD50, srgbTag()))
+}
+
+func adobeProfile() []byte {
+	return buildProfile(ColorSpaceRGB, matrixTRCTags(mlucTag("Adobe RGB (1998)"), adobeD50, gammaTag(2.19921875)))
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		colorSpace  string
+		description string
+		convertible bool
+		srgb        bool
+	}{
+		{name: "sRGB", data: srgbProfile(), colorSpace: ColorSpaceRGB, description: "sRGB IEC61966-2.1", convertible: true, srgb: true},
+		{name: "Adobe RGB", data: adobeProfile(), colorSpace: ColorSpaceRGB, description: "Adobe RGB (1998)", convertible: true},
+		{
+			name:        "LUT sRGB",
+			data:        buildProfile(ColorSpaceRGB, []testTag{{signature: tagDescription, data: descTag("sRGB v4 LUT")}}),
+			colorSpace:  ColorSpaceRGB,
+			description: "sRGB v4 LUT",
+			srgb:        true,
+		},
+		{
+			name:        "gray",
+			data:        buildProfile(ColorSpaceGray, []testTag{{signature: tagDescription, data: descTag("Dot Gain 20%")}}),
+			colorSpace:  ColorSpaceGray,
+			description: "Dot Gain 20%",
+		},
+		{
+			name:       "unknown curve",
+			data:       buildProfile(ColorSpaceRGB, matrixTRCTags(descTag(""), adobeD50, []byte("sf32\x00\x00\x00\x00\x00\x00\x00\x00"))),
+			colorSpace: ColorSpaceRGB,
+		},
+	}
+
+	for _, c := range cases {
+		profile, err := Parse(c.data)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if profile.ColorSpace != c.colorSpace || profile.Description != c.description {
+			t.Errorf("%s: got %q %q, want %q %q", c.name, profile.ColorSpace, profile.Description, c.colorSpace, c.description)
+		}
+
+		if profile.IsConvertible() != c.convertible || profile.IsSRGB() != c.srgb {
+			t.Errorf("%s: convertible %t, sRGB %t", c.name, profile.IsConvertible(), profile.IsSRGB())
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	valid := adobeProfile()
+
+	tooManyTags := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(tooManyTags[headerSize:], maxTagCount+1)
+
+	truncatedTable := bytes.Clone(valid[:headerSize+4+tagEntrySize])
+
+	tagOutside := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(tagOutside[headerSize+4+4:], uint32(len(valid)))
+
+	tagSizeOverflow := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(tagSizeOverflow[headerSize+4+8:], 0xFFFFFFFF)
+
+	cases := map[string][]byte{
+		"empty":             nil,
+		"header only":       valid[:headerSize],
+		"too many tags":     tooManyTags,
+		"truncated table":   truncatedTable,
+		"tag outside":       tagOutside,
+		"tag size overflow": tagSizeOverflow,
+	}
+
+	for name, data := range cases {
+		if _, err := Parse(data); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidProfile)
+		}
+	}
+}
+
+func TestToSRGB(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+	img.SetRGBA(1, 0, color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF})
+	img.SetRGBA(2, 0, color.RGBA{R: 0x40, G: 0xA0, B: 0x20, A: 0xFF})
+
+	srgb, err := Parse(srgbProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if srgb.ToSRGB(img) != image.Image(img) {
+		t.Error("sRGB image is converted")
+	}
+
+	adobe, err := Parse(adobeProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converted := adobe.ToSRGB(img).(*image.RGBA)
+
+	// Adobe RGB and sRGB share white point, neutral colors stay neutral
+	for x := 0; x < 2; x++ {
+		pixel := converted.RGBAAt(x, 0)
+		if absDiff(pixel.R, pixel.G) > 1 || absDiff(pixel.G, pixel.B) > 1 || absDiff(pixel.R, img.RGBAAt(x, 0).R) > 3 {
+			t.Errorf("neutral %v converted to %v", img.RGBAAt(x, 0), pixel)
+		}
+	}
+
+	// Adobe RGB green is more saturated, so red and blue of sRGB pixel go down and green goes up
+	source, pixel := img.RGBAAt(2, 0), converted.RGBAAt(2, 0)
+	if pixel.G <= source.G || pixel.R >= source.R {
+		t.Errorf("green %v converted to %v", source, pixel)
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
+
+func sampleJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestJPEGRoundTrip(t *testing.T) {
+	// Profile larger than single APP2 segment is split into chunks
+	large := append(adobeProfile(), make([]byte, 2*maxChunkSize)...)
+
+	for name, profile := range map[string][]byte{"single chunk": srgbProfile(), "three chunks": large} {
+		embedded, err := EmbedJPEG(sampleJPEG(t), profile)
+		if err != nil {
+			t.Fatalf("%s: EmbedJPEG: %v", name, err)
+		}
+
+		// Embedding again replaces profile instead of adding one
+		embedded, err = EmbedJPEG(embedded, profile)
+		if err != nil {
+			t.Fatalf("%s: EmbedJPEG again: %v", name, err)
+		}
+
+		extracted, err := ExtractJPEG(embedded)
+		if err != nil {
+			t.Fatalf("%s: ExtractJPEG: %v", name, err)
+		}
+
+		if !bytes.Equal(extracted, profile) {
+			t.Errorf("%s: extracted %d bytes, want %d", name, len(extracted), len(profile))
+		}
+
+		if _, err := jpeg.Decode(bytes.NewReader(embedded)); err != nil {
+			t.Errorf("%s: jpeg.Decode: %v", name, err)
+		}
+	}
+}
+
+func TestExtractJPEGMalformed(t *testing.T) {
+	plain := sampleJPEG(t)
+
+	if _, err := ExtractJPEG(plain); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("plain: got %v, want %v", err, ErrNoProfile)
+	}
+
+	if _, err := ExtractJPEG(plain[:10]); !errors.Is(err, exif.ErrInvalidJPEG) {
+		t.Errorf("truncated: got %v, want %v", err, exif.ErrInvalidJPEG)
+	}
+
+	embedded, err := EmbedJPEG(plain, append(adobeProfile(), make([]byte, 2*maxChunkSize)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segments, scan, err := exif.ReadSegments(embedded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withoutChunk := make([]exif.Segment, 0, len(segments))
+	renumbered := make([]exif.Segment, 0, len(segments))
+	truncatedChunk := make([]exif.Segment, 0, len(segments))
+
+	for _, segment := range segments {
+		if !isICCSegment(segment) {
+			withoutChunk = append(withoutChunk, segment)
+			renumbered = append(renumbered, segment)
+			truncatedChunk = append(truncatedChunk, segment)
+
+			continue
+		}
+
+		sequence := segment.Data[len(iccHeader)]
+		if sequence != 2 {
+			withoutChunk = append(withoutChunk, segment)
+		}
+
+		changed := exif.Segment{Marker: segment.Marker, Data: bytes.Clone(segment.Data)}
+		if sequence == 3 {
+			changed.Data[len(iccHeader)] = 4
+		}
+
+		renumbered = append(renumbered, changed)
+
+		if sequence == 1 {
+			truncatedChunk = append(truncatedChunk, exif.Segment{Marker: segment.Marker, Data: iccHeader})
+		} else {
+			truncatedChunk = append(truncatedChunk, segment)
+		}
+	}
+
+	for name, broken := range map[string][]exif.Segment{
+		"missing chunk":   withoutChunk,
+		"chunk sequence":  renumbered,
+		"truncated chunk": truncatedChunk,
+	} {
+		data, err := exif.WriteSegments(broken, scan)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ExtractJPEG(data); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidProfile)
+		}
+	}
+}
+
+func TestEmbedJPEGTooLarge(t *testing.T) {
+	if _, err := EmbedJPEG(sampleJPEG(t), make([]byte, 256*maxChunkSize)); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("got %v, want %v", err, ErrInvalidProfile)
+	}
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngWithProfile inserts iCCP chunk right after IHDR of encoded PNG
+func pngWithProfile(t *testing.T, profile []byte) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := new(bytes.Buffer)
+	writer := zlib.NewWriter(compressed)
+	if _, err := writer.Write(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ihdrEnd := len(pngHeader) + 12 + 13
+	data := buf.Bytes()
+
+	result := append([]byte(nil), data[:ihdrEnd]...)
+	result = append(result, pngChunk("iCCP", append([]byte("icc\x00\x00"), compressed.Bytes()...))...)
+
+	return append(result, data[ihdrEnd:]...)
+}
+
+func TestExtractPNG(t *testing.T) {
+	profile := adobeProfile()
+	data := pngWithProfile(t, profile)
+
+	extracted, err := ExtractPNG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(extracted, profile) {
+		t.Errorf("extracted %d bytes, want %d", len(extracted), len(profile))
+	}
+
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("png.Decode: %v", err)
+	}
+}
+
+func TestExtractPNGMalformed(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ExtractPNG(buf.Bytes()); !errors.Is(err, ErrNoProfile) {
+		t.Errorf("plain: got %v, want %v", err, ErrNoProfile)
+	}
+
+	withProfile := pngWithProfile(t, adobeProfile())
+
+	chunkStart := len(pngHeader) + 12 + 13
+
+	oversizedChunk := bytes.Clone(withProfile)
+	binary.BigEndian.PutUint32(oversizedChunk[chunkStart:], 0x7FFFFFFF)
+
+	noName := bytes.Clone(withProfile[:chunkStart])
+	noName = append(noName, pngChunk("iCCP", []byte("icc"))...)
+
+	cases := map[string][]byte{
+		"not png":         sampleJPEG(t),
+		"truncated chunk": withProfile[:chunkStart+20],
+		"oversized chunk": oversizedChunk,
+		"no name end":     noName,
+	}
+
+	for name, data := range cases {
+		if _, err := ExtractPNG(data); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidProfile)
+		}
+	}
+
+	brokenStream := bytes.Clone(withProfile[:chunkStart])
+	brokenStream = append(brokenStream, pngChunk("iCCP", []byte("icc\x00\x00not zlib"))...)
+
+	if _, err := ExtractPNG(brokenStream); err == nil {
+		t.Error("broken zlib stream accepted")
+	}
+}
@@ -4,6 +4,17 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"test-task-photo-booth/src/entities/customErrors"
+)
+
+const (
+	dataURIPrefix    = "data:"
+	dataURIB64Suffix = ";base64"
+
+	// mimeTypeJPGAlias is non-standard jpeg mime type, still sent by many clients
+	mimeTypeJPGAlias = "image/jpg"
 )
 
 func GetB64MimeType(data []byte) string {
@@ -23,3 +34,65 @@ func GetB64WithMimeType(data []byte) string {
 
 	return b64DataWithMimeType
 }
+
+// DecodeB64DataURI decodes plain b64 or data URI (data:image/jpeg;base64,...) produced by GetB64WithMimeType
+// or canvas.toDataURL. Standard, URL-safe and unpadded b64 alphabets are accepted.
+// Returns decoded bytes and sniffed mime type, declared mime type must match sniffed one
+func DecodeB64DataURI(data string) ([]byte, string, error) {
+	data = strings.TrimSpace(data)
+
+	declaredMimeType := ""
+	if strings.HasPrefix(data, dataURIPrefix) {
+		header, payload, found := strings.Cut(data, ",")
+		if !found {
+			return nil, "", customErrors.ErrInvalidDataURI
+		}
+
+		header = strings.TrimPrefix(header, dataURIPrefix)
+		if !strings.HasSuffix(header, dataURIB64Suffix) {
+			return nil, "", customErrors.ErrInvalidDataURI
+		}
+
+		declaredMimeType = strings.TrimSuffix(header, dataURIB64Suffix)
+		// drop optional media type params e.g. "image/jpeg;name=photo.jpg"
+		declaredMimeType, _, _ = strings.Cut(declaredMimeType, ";")
+		if strings.EqualFold(declaredMimeType, mimeTypeJPGAlias) {
+			declaredMimeType = "image/jpeg"
+		}
+
+		data = payload
+	}
+
+	decodedData, err := decodeB64AnyEncoding(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mimeType := GetB64MimeType(decodedData)
+	if declaredMimeType != "" && !strings.EqualFold(declaredMimeType, mimeType) {
+		return nil, "", fmt.Errorf("%w: declared %s, detected %s", customErrors.ErrMimeTypeMismatch, declaredMimeType, mimeType)
+	}
+
+	return decodedData, mimeType, nil
+}
+
+func decodeB64AnyEncoding(data string) ([]byte, error) {
+	// data URIs may be wrapped or url-encoded by some clients
+	data = strings.NewReplacer("\n", "", "\r", "", " ", "", "%3D", "=", "%3d", "=").Replace(data)
+
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(data, "-_") {
+		encoding = base64.URLEncoding
+	}
+
+	if !strings.HasSuffix(data, "=") {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+
+	decodedData, err := encoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("base64 DecodeString() failed: %w", err)
+	}
+
+	return decodedData, nil
+}
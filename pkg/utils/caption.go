@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+
+	"github.com/nfnt/resize"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Built-in caption fonts
+const (
+	CaptionFontBasic   = "basic"   // 7x13 bitmap font, scaled to size
+	CaptionFontRegular = "regular" // bundled Go Regular
+)
+
+// Caption positions, same as watermark ones plus top and bottom centered
+const (
+	CaptionTop    = "top"
+	CaptionBottom = "bottom"
+)
+
+// IsCaptionPositionSupported reports whether position can be used for caption
+func IsCaptionPositionSupported(position string) bool {
+	return position == CaptionTop || position == CaptionBottom || IsWatermarkPositionSupported(position)
+}
+
+// CaptionFont is parsed TrueType/OpenType font, nil Font is built-in basic font
+type CaptionFont struct {
+	Font *opentype.Font
+}
+
+// ParseCaptionFont parses TrueType or OpenType font file
+func ParseCaptionFont(data []byte) (CaptionFont, error) {
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return CaptionFont{}, fmt.Errorf("opentype.Parse() failed: %w", err)
+	}
+
+	return CaptionFont{Font: parsed}, nil
+}
+
+// BuiltinCaptionFont returns built-in font by name
+func BuiltinCaptionFont(name string) (CaptionFont, bool) {
+	switch name {
+	case CaptionFontBasic:
+		return CaptionFont{}, true
+	case CaptionFontRegular:
+		parsed, err := ParseCaptionFont(goregular.TTF)
+
+		return parsed, err == nil
+	default:
+		return CaptionFont{}, false
+	}
+}
+
+// CaptionOptions describe caption look, sizes are in pixels of image caption is drawn on
+type CaptionOptions struct {
+	Font         CaptionFont
+	Size         float64 // line height
+	Color        color.Color
+	OutlineColor color.Color
+	Outline      int // outline width, 0 for none
+	Position     string
+	Margin       int
+	Area         image.Rectangle // caption is placed within area, whole image when empty
+}
+
+// Caption draws text over copy of image, lines are split by "\n" and aligned by position
+func Caption(img image.Image, text string, options CaptionOptions) (image.Image, error) {
+	bounds := img.Bounds()
+
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+
+	layer, err := captionLayer(text, options)
+	if err != nil {
+		return nil, fmt.Errorf("captionLayer() failed: %w", err)
+	}
+
+	area := options.Area
+	if area.Empty() {
+		area = result.Bounds()
+	}
+
+	origin := area.Min.Add(captionOrigin(area.Size(), layer.Bounds().Size(), options.Position, options.Margin))
+	draw.Draw(result, layer.Bounds().Add(origin), layer, image.Point{}, draw.Over)
+
+	return result, nil
+}
+
+// captionLayer renders outlined text on transparent image
+func captionLayer(text string, options CaptionOptions) (image.Image, error) {
+	size := max(options.Size, 1)
+
+	face, scale, err := captionFace(options.Font, size)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	outline := int(math.Round(float64(options.Outline) / scale))
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	lines := strings.Split(text, "\n")
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line).Ceil())
+	}
+
+	layer := image.NewRGBA(image.Rect(0, 0, width+2*outline, lineHeight*len(lines)+2*outline))
+
+	for i, line := range lines {
+		lineWidth := font.MeasureString(face, line).Ceil()
+		x := outline + captionLineOffset(width, lineWidth, options.Position)
+		baseline := outline + i*lineHeight + metrics.Ascent.Ceil()
+
+		if outline > 0 {
+			for dy := -outline; dy <= outline; dy++ {
+				for dx := -outline; dx <= outline; dx++ {
+					if dx*dx+dy*dy > outline*outline {
+						continue
+					}
+
+					drawCaptionLine(layer, face, line, options.OutlineColor, x+dx, baseline+dy)
+				}
+			}
+		}
+
+		drawCaptionLine(layer, face, line, options.Color, x, baseline)
+	}
+
+	if scale == 1 {
+		return layer, nil
+	}
+
+	// bitmap font has single size, it's scaled up as a whole
+	layerBounds := layer.Bounds()
+	scaledWidth := uint(math.Max(1, math.Round(float64(layerBounds.Dx())*scale)))
+	scaledHeight := uint(math.Max(1, math.Round(float64(layerBounds.Dy())*scale)))
+
+	return resize.Resize(scaledWidth, scaledHeight, layer, resize.NearestNeighbor), nil
+}
+
+// captionFace returns face of font and scale layer rendered with it should be scaled by
+func captionFace(captionFont CaptionFont, size float64) (font.Face, float64, error) {
+	if captionFont.Font == nil {
+		return basicfont.Face7x13, size / float64(basicfont.Face7x13.Height), nil
+	}
+
+	face, err := opentype.NewFace(captionFont.Font, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72, // size in points is size in pixels
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("opentype.NewFace() failed: %w", err)
+	}
+
+	return face, 1, nil
+}
+
+func drawCaptionLine(dst draw.Image, face font.Face, line string, c color.Color, x, baseline int) {
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, baseline),
+	}
+	drawer.DrawString(line)
+}
+
+// captionLineOffset aligns line within caption block by horizontal part of position
+func captionLineOffset(blockWidth, lineWidth int, position string) int {
+	switch position {
+	case WatermarkTopLeft, WatermarkBottomLeft:
+		return 0
+	case WatermarkTopRight, WatermarkBottomRight:
+		return blockWidth - lineWidth
+	default:
+		return (blockWidth - lineWidth) / 2
+	}
+}
+
+func captionOrigin(area, caption image.Point, position string, margin int) image.Point {
+	centerX := (area.X - caption.X) / 2
+
+	switch position {
+	case CaptionTop:
+		return image.Pt(centerX, margin)
+	case CaptionBottom:
+		return image.Pt(centerX, area.Y-caption.Y-margin)
+	default:
+		return watermarkOrigin(area, caption, position, margin)
+	}
+}
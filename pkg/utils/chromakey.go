@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// ChromaKeyOptions describes key color and how far from it pixels are replaced by background,
+// Tolerance and Softness are fractions of chroma range 0-1
+type ChromaKeyOptions struct {
+	Key       color.Color
+	Tolerance float64 // pixels closer to key are fully replaced
+	Softness  float64 // band after tolerance where photo fades into background
+}
+
+// ChromaKey replaces pixels of key color by background scaled to cover photo, key color spill is removed from edges.
+// Distance is measured by chroma only, so shadows and highlights on backdrop are keyed as well
+func ChromaKey(img, background image.Image, options ChromaKeyOptions) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	foreground := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(foreground, foreground.Bounds(), img, bounds.Min, draw.Src)
+
+	backdrop := image.NewNRGBA(image.Rect(0, 0, width, height))
+	cover := Thumbnail(background, width, height, ResizeModeFill, color.Black)
+	draw.Draw(backdrop, backdrop.Bounds(), cover, cover.Bounds().Min, draw.Src)
+
+	keyR, keyG, keyB, _ := options.Key.RGBA()
+	_, keyCb, keyCr := color.RGBToYCbCr(uint8(keyR>>8), uint8(keyG>>8), uint8(keyB>>8))
+	spillChannel := dominantChannel(uint8(keyR>>8), uint8(keyG>>8), uint8(keyB>>8))
+
+	for i := 0; i < len(foreground.Pix); i += 4 {
+		pixel := foreground.Pix[i : i+4 : i+4]
+
+		_, cb, cr := color.RGBToYCbCr(pixel[0], pixel[1], pixel[2])
+		distance := math.Hypot(float64(cb)-float64(keyCb), float64(cr)-float64(keyCr)) / math.MaxUint8
+
+		alpha := keyAlpha(distance, options.Tolerance, options.Softness)
+		if alpha == 1 {
+			continue
+		}
+
+		// edges keep reflected backdrop color, it's clamped to other channels
+		others := pixel[:3:3]
+		limit := max(others[(spillChannel+1)%3], others[(spillChannel+2)%3])
+		pixel[spillChannel] = min(pixel[spillChannel], limit)
+
+		under := backdrop.Pix[i : i+4 : i+4]
+		for channel := 0; channel < 3; channel++ {
+			pixel[channel] = uint8(math.Round(float64(pixel[channel])*alpha + float64(under[channel])*(1-alpha)))
+		}
+	}
+
+	return foreground
+}
+
+// keyAlpha is photo opacity by chroma distance from key
+func keyAlpha(distance, tolerance, softness float64) float64 {
+	switch {
+	case distance <= tolerance:
+		return 0
+	case softness <= 0 || distance >= tolerance+softness:
+		return 1
+	default:
+		return (distance - tolerance) / softness
+	}
+}
+
+func dominantChannel(r, g, b uint8) int {
+	switch {
+	case g >= r && g >= b:
+		return 1
+	case b >= r:
+		return 2
+	default:
+		return 0
+	}
+}
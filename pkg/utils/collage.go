@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/nfnt/resize"
+)
+
+// Collage layouts
+const (
+	CollageStrip = "strip" // single column
+	CollageGrid  = "grid"  // square-ish grid
+	Collage2x2   = "2x2"   // two columns, up to 4 photos
+)
+
+const collage2x2MaxPhotos = 4
+
+// CollageOptions describes collage canvas, photos are cropped to fill CellWidth x CellHeight cells
+type CollageOptions struct {
+	Layout     string
+	CellWidth  int
+	CellHeight int
+	Spacing    int // between cells and around them
+	Header     int // space above cells, covered by frame
+	Footer     int // space below cells, covered by frame
+	Background color.Color
+	Frame      image.Image // drawn over whole canvas, nil for none
+}
+
+// CollageGridSize returns columns and rows of layout for count photos, false if layout can't hold them
+func CollageGridSize(layout string, count int) (int, int, bool) {
+	if count < 1 {
+		return 0, 0, false
+	}
+
+	switch layout {
+	case CollageStrip:
+		return 1, count, true
+	case Collage2x2:
+		if count > collage2x2MaxPhotos {
+			return 0, 0, false
+		}
+
+		return 2, (count + 1) / 2, true
+	case CollageGrid:
+		columns := int(math.Ceil(math.Sqrt(float64(count))))
+
+		return columns, (count + columns - 1) / columns, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// Collage composes photos into single image according to options, photos go left to right, top to bottom
+func Collage(images []image.Image, options CollageOptions) image.Image {
+	columns, rows, ok := CollageGridSize(options.Layout, len(images))
+	if !ok {
+		columns, rows = 1, len(images)
+	}
+
+	width := columns*options.CellWidth + (columns+1)*options.Spacing
+	height := options.Header + rows*options.CellHeight + (rows+1)*options.Spacing + options.Footer
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(options.Background), image.Point{}, draw.Src)
+
+	for i, img := range images {
+		column, row := i%columns, i/columns
+
+		origin := image.Pt(
+			options.Spacing+column*(options.CellWidth+options.Spacing),
+			options.Header+options.Spacing+row*(options.CellHeight+options.Spacing),
+		)
+
+		cell := Thumbnail(img, options.CellWidth, options.CellHeight, ResizeModeFill, options.Background)
+		draw.Draw(canvas, cell.Bounds().Sub(cell.Bounds().Min).Add(origin), cell, cell.Bounds().Min, draw.Over)
+	}
+
+	if options.Frame != nil {
+		frame := resize.Resize(uint(width), uint(height), options.Frame, resize.Lanczos3)
+		draw.Draw(canvas, canvas.Bounds(), frame, frame.Bounds().Min, draw.Over)
+	}
+
+	return canvas
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Frame crops photo to fill region of frame and draws frame over it, result has frame size.
+// Areas of frame which are transparent and out of region show background
+func Frame(img, frame image.Image, region image.Rectangle, background color.Color) image.Image {
+	bounds := frame.Bounds()
+
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	placed := Thumbnail(img, region.Dx(), region.Dy(), ResizeModeFill, background)
+	draw.Draw(canvas, region, placed, placed.Bounds().Min, draw.Over)
+	draw.Draw(canvas, canvas.Bounds(), frame, bounds.Min, draw.Over)
+
+	return canvas
+}
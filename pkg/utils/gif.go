@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"sort"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	gifMaxColors       = 256
+	gifPaletteSample   = 64 // frames are downscaled to sample palette colors
+	gifDelayUnitMillis = 10 // GIF delay is in 1/100 s
+	gifMinDelay        = 2  // browsers slow down faster frames to 100ms
+	gifLoopForever     = 0
+)
+
+// BoomerangOptions describes animated GIF, frames are scaled to Width keeping aspect ratio of first frame
+type BoomerangOptions struct {
+	Width   int
+	DelayMs int
+	Colors  int  // palette size shared by all frames, up to 256
+	Dither  bool // Floyd-Steinberg dithering
+}
+
+// Boomerang builds looping animation playing frames forward and then backward, ends aren't repeated
+func Boomerang(frames []image.Image, options BoomerangOptions) *gif.GIF {
+	first := frames[0].Bounds()
+	width := options.Width
+	height := max(first.Dy()*width/max(first.Dx(), 1), 1)
+
+	scaled := make([]image.Image, 0, len(frames))
+	for _, frame := range frames {
+		scaled = append(scaled, Thumbnail(frame, width, height, ResizeModeFill, color.White))
+	}
+
+	// Single palette for all frames keeps colors from flickering between frames
+	palette := QuantizePalette(scaled, options.Colors)
+
+	var drawer draw.Drawer = draw.Src
+	if options.Dither {
+		drawer = draw.FloydSteinberg
+	}
+
+	paletted := make([]*image.Paletted, 0, len(scaled))
+	for _, frame := range scaled {
+		target := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		drawer.Draw(target, target.Bounds(), frame, frame.Bounds().Min)
+
+		paletted = append(paletted, target)
+	}
+
+	sequence := append([]*image.Paletted{}, paletted...)
+	for i := len(paletted) - 2; i > 0; i-- {
+		sequence = append(sequence, paletted[i])
+	}
+
+	delay := max(options.DelayMs/gifDelayUnitMillis, gifMinDelay)
+
+	animation := &gif.GIF{
+		Image:     sequence,
+		Delay:     make([]int, len(sequence)),
+		LoopCount: gifLoopForever,
+	}
+
+	for i := range animation.Delay {
+		animation.Delay[i] = delay
+	}
+
+	return animation
+}
+
+// EncodeGIF encodes animated GIF
+func EncodeGIF(animation *gif.GIF) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return nil, fmt.Errorf("gif.EncodeAll() failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeGIFFrames decodes all frames of animated GIF as full canvas images, frame disposal is applied
+func DecodeGIFFrames(data []byte) ([]image.Image, error) {
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gif.DecodeAll() failed: %w", err)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, animation.Config.Width, animation.Config.Height))
+	frames := make([]image.Image, 0, len(animation.Image))
+
+	for i, frame := range animation.Image {
+		var previous *image.RGBA
+
+		disposal := byte(gif.DisposalNone)
+		if i < len(animation.Disposal) {
+			disposal = animation.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		frames = append(frames, cloneRGBA(canvas))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames, nil
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := image.NewRGBA(img.Bounds())
+	copy(clone.Pix, img.Pix)
+
+	return clone
+}
+
+// QuantizePalette picks up to count colors representing all images by median cut
+func QuantizePalette(images []image.Image, count int) color.Palette {
+	count = min(max(count, 2), gifMaxColors)
+
+	pixels := make([][3]uint8, 0, len(images)*gifPaletteSample*gifPaletteSample)
+
+	for _, img := range images {
+		sample := resize.Thumbnail(gifPaletteSample, gifPaletteSample, img, resize.Bilinear)
+		bounds := sample.Bounds()
+
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, _ := sample.At(x, y).RGBA()
+				pixels = append(pixels, [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)})
+			}
+		}
+	}
+
+	boxes := [][][3]uint8{pixels}
+
+	for len(boxes) < count {
+		widest, channel, span := -1, 0, 0
+
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+
+			if boxChannel, boxSpan := widestChannel(box); boxSpan > span {
+				widest, channel, span = i, boxChannel, boxSpan
+			}
+		}
+
+		// every box holds single color
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		sort.Slice(box, func(i, j int) bool { return box[i][channel] < box[j][channel] })
+
+		median := len(box) / 2
+		boxes[widest] = box[:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+
+	for _, box := range boxes {
+		if len(box) == 0 {
+			continue
+		}
+
+		var sum [3]int
+		for _, pixel := range box {
+			sum[0] += int(pixel[0])
+			sum[1] += int(pixel[1])
+			sum[2] += int(pixel[2])
+		}
+
+		palette = append(palette, color.RGBA{
+			R: uint8(sum[0] / len(box)),
+			G: uint8(sum[1] / len(box)),
+			B: uint8(sum[2] / len(box)),
+			A: 0xff,
+		})
+	}
+
+	return palette
+}
+
+// widestChannel returns channel with largest value range in box and the range
+func widestChannel(box [][3]uint8) (int, int) {
+	lowest := [3]uint8{255, 255, 255}
+	highest := [3]uint8{}
+
+	for _, pixel := range box {
+		for c := range pixel {
+			lowest[c] = min(lowest[c], pixel[c])
+			highest[c] = max(highest[c], pixel[c])
+		}
+	}
+
+	channel, span := 0, 0
+	for c := range lowest {
+		if int(highest[c])-int(lowest[c]) > span {
+			channel, span = c, int(highest[c])-int(lowest[c])
+		}
+	}
+
+	return channel, span
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// EXIF orientation values
+const (
+	orientationFlipH       = 2
+	orientationRotate180   = 3
+	orientationFlipV       = 4
+	orientationTranspose   = 5
+	orientationRotate90CW  = 6
+	orientationTransverse  = 7
+	orientationRotate90CCW = 8
+)
+
+// ApplyOrientation rotates/flips image according to EXIF orientation, so it's displayed upright without EXIF
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < orientationFlipH || orientation > orientationRotate90CCW {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= orientationTranspose {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := orientedPoint(orientation, x, y, width, height)
+			dst.SetNRGBA(dx, dy, src.NRGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
+
+// orientedPoint maps source pixel to its place in upright image
+func orientedPoint(orientation, x, y, width, height int) (int, int) {
+	switch orientation {
+	case orientationFlipH:
+		return width - 1 - x, y
+	case orientationRotate180:
+		return width - 1 - x, height - 1 - y
+	case orientationFlipV:
+		return x, height - 1 - y
+	case orientationTranspose:
+		return y, x
+	case orientationRotate90CW:
+		return height - 1 - y, x
+	case orientationTransverse:
+		return height - 1 - y, width - 1 - x
+	case orientationRotate90CCW:
+		return y, width - 1 - x
+	default:
+		return x, y
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+
+	"github.com/nfnt/resize"
+)
+
+// dHash compares neighbour pixels of 9x8 grayscale thumbnail, giving 64 bit hash
+const (
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// DHash returns perceptual difference hash of image, visually similar images have hashes with small Hamming distance
+func DHash(img image.Image) uint64 {
+	small := resize.Resize(dHashWidth, dHashHeight, img, resize.Bilinear)
+	bounds := small.Bounds()
+
+	var hash uint64
+
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			left := luminance(small.At(bounds.Min.X+x, bounds.Min.Y+y))
+			right := luminance(small.At(bounds.Min.X+x+1, bounds.Min.Y+y))
+
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// HammingDistance returns number of differing bits of two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func luminance(c color.Color) uint8 {
+	return color.GrayModel.Convert(c).(color.Gray).Y
+}
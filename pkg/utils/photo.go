@@ -5,63 +5,145 @@ import (
 	"encoding/base64"
 	"fmt"
 	"image"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 
-	"github.com/nfnt/resize"
+	"github.com/HugoSmits86/nativewebp"
 )
 
-func ResizeImageB64(dataB64, extension string, percentage uint) (string, error) {
-	var resizedImageB64 string
+const (
+	MimeTypeJPEG = "image/jpeg"
+	MimeTypePNG  = "image/png"
+	MimeTypeWebP = "image/webp"
+	MimeTypeGIF  = "image/gif"
+)
 
-	b, err := base64.StdEncoding.DecodeString(dataB64)
-	if err != nil {
-		return "", fmt.Errorf("base64.StdEncoding.DecodeString() failed: %w", err)
-	}
+const VariantJPEGQuality = 20
+
+// IsSupportedPhotoMimeType reports whether photo of mimeType can be processed
+func IsSupportedPhotoMimeType(mimeType string) bool {
+	return mimeType == MimeTypeJPEG || mimeType == MimeTypePNG
+}
+
+// DecodeImage decodes JPEG, PNG or first frame of GIF photo, returns image and sniffed mime type
+func DecodeImage(data []byte) (image.Image, string, error) {
+	extension := GetB64MimeType(data)
 
 	switch extension {
-	case "image/jpeg":
-		img, err := jpeg.Decode(bytes.NewReader(b))
+	case MimeTypeJPEG:
+		img, err := jpeg.Decode(bytes.NewReader(data))
 		if err != nil {
-			return "", fmt.Errorf("jpeg.Decode() failed: %w", err)
+			return nil, "", fmt.Errorf("jpeg.Decode() failed: %w", err)
 		}
 
-		width, height := getResizedImageBounds(img, percentage)
-		resImag := resize.Resize(width, height, img, resize.Lanczos3)
+		return img, extension, nil
+	case MimeTypePNG:
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("png.Decode() failed: %w", err)
+		}
 
-		buf := new(bytes.Buffer)
-		if err := jpeg.Encode(buf, resImag, &jpeg.Options{Quality: 20}); err != nil {
-			return "", fmt.Errorf("jpeg.Decode() failed: %w", err)
+		return img, extension, nil
+	case MimeTypeGIF:
+		img, err := gif.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("gif.Decode() failed: %w", err)
 		}
 
-		resizedImageB64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+		return img, extension, nil
+	default:
+		return nil, "", fmt.Errorf("unknown extension")
+	}
+}
+
+// ResizeImage scales image to percentage of its original size with processor
+func ResizeImage(processor ImageProcessor, img image.Image, percentage uint) image.Image {
+	width, height := getResizedImageBounds(img, percentage)
+
+	return processor.Resize(img, width, height)
+}
+
+// EncodeImageB64 encodes variant image as JPEG in b64
+func EncodeImageB64(img image.Image) (string, error) {
+	data, err := EncodeJPEG(img, VariantJPEGQuality)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// EncodeJPEG encodes image as JPEG with quality 1-100
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("jpeg.Encode() failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeJPEGWithin encodes image as JPEG with highest quality from minQuality to maxQuality which fits maxBytes.
+// Quality is found by binary search, image which doesn't fit even at minQuality is returned at minQuality.
+// Returns data, quality and whether data fits maxBytes
+func EncodeJPEGWithin(img image.Image, maxBytes, minQuality, maxQuality int) ([]byte, int, bool, error) {
+	var best []byte
 
-	case "image/png":
-		img, err := png.Decode(bytes.NewReader(b))
+	bestQuality := 0
+	low, high := minQuality, maxQuality
+
+	for low <= high {
+		quality := (low + high + 1) / 2
+
+		data, err := EncodeJPEG(img, quality)
 		if err != nil {
-			return "", fmt.Errorf("jpeg.Decode() failed: %w", err)
+			return nil, 0, false, err
 		}
 
-		width, height := getResizedImageBounds(img, percentage)
-		resImag := resize.Resize(width, height, img, resize.Lanczos3)
-
-		buf := new(bytes.Buffer)
-		if err := jpeg.Encode(buf, resImag, &jpeg.Options{Quality: 20}); err != nil {
-			return "", fmt.Errorf("jpeg.Encode() failed: %w", err)
+		if len(data) <= maxBytes {
+			best, bestQuality = data, quality
+			low = quality + 1
+		} else {
+			high = quality - 1
 		}
+	}
+
+	if best != nil {
+		return best, bestQuality, true, nil
+	}
 
-		resizedImageB64 = base64.StdEncoding.EncodeToString(buf.Bytes())
+	data, err := EncodeJPEG(img, minQuality)
+	if err != nil {
+		return nil, 0, false, err
+	}
 
-	default:
-		return "", fmt.Errorf("unknown extension")
+	return data, minQuality, false, nil
+}
+
+// EncodePNG encodes image as PNG
+func EncodePNG(img image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("png.Encode() failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeWebP encodes image as lossless WebP, pure Go encoder has no lossy mode
+func EncodeWebP(img image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := nativewebp.Encode(buf, img, nil); err != nil {
+		return nil, fmt.Errorf("nativewebp.Encode() failed: %w", err)
 	}
 
-	return resizedImageB64, nil
+	return buf.Bytes(), nil
 }
 
-func getResizedImageBounds(img image.Image, percentage uint) (uint, uint) {
-	width := uint(float64(img.Bounds().Dx()) * float64(percentage) / 100)
-	height := uint(float64(img.Bounds().Dy()) * float64(percentage) / 100)
+func getResizedImageBounds(img image.Image, percentage uint) (int, int) {
+	width := int(float64(img.Bounds().Dx()) * float64(percentage) / 100)
+	height := int(float64(img.Bounds().Dy()) * float64(percentage) / 100)
 
 	return width, height
 }
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	blurHashSampleSize = 32 // BlurHash keeps only low frequencies, small sample gives same hash
+	paletteSampleSize  = 64
+
+	paletteBucketShift   = 4    // 16 levels per channel
+	paletteMinDistanceSq = 1600 // colors closer than 40 in RGB space are merged
+)
+
+// MaxBlurHashComponents is max number of BlurHash components per axis
+const MaxBlurHashComponents = 9
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// BlurHash encodes image to BlurHash string (https://blurha.sh) with xComponents x yComponents 1-9
+func BlurHash(img image.Image, xComponents, yComponents int) (string, error) {
+	if xComponents < 1 || xComponents > MaxBlurHashComponents || yComponents < 1 || yComponents > MaxBlurHashComponents {
+		return "", fmt.Errorf("blurhash components must be 1-%d", MaxBlurHashComponents)
+	}
+
+	sample := resize.Thumbnail(blurHashSampleSize, blurHashSampleSize, img, resize.Bilinear)
+	bounds := sample.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width == 0 || height == 0 {
+		return "", fmt.Errorf("empty image")
+	}
+
+	linear := make([][3]float64, width*height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := sample.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			linear[y*width+x] = [3]float64{sRGBToLinear(r >> 8), sRGBToLinear(g >> 8), sRGBToLinear(b >> 8)}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var factor [3]float64
+
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i*x)/float64(width)) * math.Cos(math.Pi*float64(j*y)/float64(height))
+					pixel := linear[y*width+x]
+
+					factor[0] += basis * pixel[0]
+					factor[1] += basis * pixel[1]
+					factor[2] += basis * pixel[2]
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	hash := new(strings.Builder)
+	writeBase83(hash, (xComponents-1)+(yComponents-1)*MaxBlurHashComponents, 1)
+
+	dc, ac := factors[0], factors[1:]
+	maximumValue := 1.0
+
+	if len(ac) > 0 {
+		actualMaximum := 0.0
+		for _, factor := range ac {
+			actualMaximum = math.Max(actualMaximum, math.Max(math.Abs(factor[0]), math.Max(math.Abs(factor[1]), math.Abs(factor[2]))))
+		}
+
+		quantisedMaximum := int(math.Max(0, math.Min(82, math.Floor(actualMaximum*166-0.5))))
+		maximumValue = float64(quantisedMaximum+1) / 166
+
+		writeBase83(hash, quantisedMaximum, 1)
+	} else {
+		writeBase83(hash, 0, 1)
+	}
+
+	writeBase83(hash, linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4)
+
+	for _, factor := range ac {
+		quantise := func(value float64) int {
+			signed := math.Copysign(math.Pow(math.Abs(value/maximumValue), 0.5), value)
+
+			return int(math.Max(0, math.Min(18, math.Floor(signed*9+9.5))))
+		}
+
+		writeBase83(hash, quantise(factor[0])*19*19+quantise(factor[1])*19+quantise(factor[2]), 2)
+	}
+
+	return hash.String(), nil
+}
+
+func writeBase83(builder *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		builder.WriteByte(base83Chars[digit])
+	}
+}
+
+func sRGBToLinear(value uint32) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+type paletteBucket struct {
+	r, g, b uint64
+	count   uint64
+}
+
+func (b paletteBucket) color() [3]uint8 {
+	return [3]uint8{uint8(b.r / b.count), uint8(b.g / b.count), uint8(b.b / b.count)}
+}
+
+// DominantColors returns up to count most frequent distinct colors of image as "#rrggbb", most frequent first
+func DominantColors(img image.Image, count int) []string {
+	sample := resize.Thumbnail(paletteSampleSize, paletteSampleSize, img, resize.Bilinear)
+	bounds := sample.Bounds()
+
+	buckets := make(map[uint32]*paletteBucket)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := sample.At(x, y).RGBA()
+			if a == 0 {
+				continue
+			}
+
+			r, g, b = r>>8, g>>8, b>>8
+			key := (r>>paletteBucketShift)<<16 | (g>>paletteBucketShift)<<8 | b>>paletteBucketShift
+
+			bucket, ok := buckets[key]
+			if !ok {
+				bucket = new(paletteBucket)
+				buckets[key] = bucket
+			}
+
+			bucket.r += uint64(r)
+			bucket.g += uint64(g)
+			bucket.b += uint64(b)
+			bucket.count++
+		}
+	}
+
+	sorted := make([]*paletteBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sorted = append(sorted, bucket)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+
+		// ties are broken by color, so palette is deterministic
+		return colorValue(sorted[i].color()) < colorValue(sorted[j].color())
+	})
+
+	picked := make([][3]uint8, 0, count)
+
+	for _, bucket := range sorted {
+		if len(picked) == count {
+			break
+		}
+
+		candidate := bucket.color()
+		if isNearColor(candidate, picked) {
+			continue
+		}
+
+		picked = append(picked, candidate)
+	}
+
+	palette := make([]string, 0, len(picked))
+	for _, c := range picked {
+		palette = append(palette, fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2]))
+	}
+
+	return palette
+}
+
+func colorValue(c [3]uint8) int {
+	return int(c[0])<<16 | int(c[1])<<8 | int(c[2])
+}
+
+func isNearColor(candidate [3]uint8, colors [][3]uint8) bool {
+	for _, c := range colors {
+		dr := int(candidate[0]) - int(c[0])
+		dg := int(candidate[1]) - int(c[1])
+		db := int(candidate[2]) - int(c[2])
+
+		if dr*dr+dg*dg+db*db < paletteMinDistanceSq {
+			return true
+		}
+	}
+
+	return false
+}
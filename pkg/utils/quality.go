@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"image"
+	"math"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	qualitySampleSize = 512 // sharpness depends on resolution, photos are measured at the same size
+
+	shadowClipLevel    = 4   // luminance at or below is clipped to black
+	highlightClipLevel = 251 // luminance at or above is clipped to white
+)
+
+// ImageQuality is technical quality of photo measured by luminance of downscaled image
+type ImageQuality struct {
+	Sharpness         float64 // variance of Laplacian, low for blurred or empty frame
+	Luminance         float64 // mean luminance 0-1
+	ShadowClipping    float64 // part of pixels clipped to black 0-1
+	HighlightClipping float64 // part of pixels clipped to white 0-1
+	Contrast          float64 // luminance standard deviation 0-1, near 0 for uniform frame
+}
+
+// MeasureQuality measures sharpness, exposure and uniformity of image
+func MeasureQuality(img image.Image) ImageQuality {
+	sample := resize.Thumbnail(qualitySampleSize, qualitySampleSize, img, resize.Bilinear)
+	bounds := sample.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width == 0 || height == 0 {
+		return ImageQuality{}
+	}
+
+	luminance := make([]float64, width*height)
+	sum, sumSq := 0.0, 0.0
+	shadows, highlights := 0, 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := sample.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			value := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+
+			luminance[y*width+x] = value
+			sum += value
+			sumSq += value * value
+
+			if value <= shadowClipLevel {
+				shadows++
+			}
+
+			if value >= highlightClipLevel {
+				highlights++
+			}
+		}
+	}
+
+	pixels := float64(width * height)
+	mean := sum / pixels
+
+	return ImageQuality{
+		Sharpness:         laplacianVariance(luminance, width, height),
+		Luminance:         mean / math.MaxUint8,
+		ShadowClipping:    float64(shadows) / pixels,
+		HighlightClipping: float64(highlights) / pixels,
+		Contrast:          math.Sqrt(math.Max(0, sumSq/pixels-mean*mean)) / math.MaxUint8,
+	}
+}
+
+// laplacianVariance convolves luminance with 4-neighbour Laplacian kernel and returns variance of response
+func laplacianVariance(luminance []float64, width, height int) float64 {
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	sum, sumSq := 0.0, 0.0
+
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			i := y*width + x
+			response := luminance[i-width] + luminance[i+width] + luminance[i-1] + luminance[i+1] - 4*luminance[i]
+
+			sum += response
+			sumSq += response * response
+		}
+	}
+
+	count := float64((width - 2) * (height - 2))
+	mean := sum / count
+
+	return sumSq/count - mean*mean
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/nfnt/resize"
+	"golang.org/x/image/draw"
+)
+
+// Resamplers of image processors
+const (
+	ResamplerLanczos3        = "lanczos3"         // nfnt/resize Lanczos3, sharpest, resizes in parallel
+	ResamplerCatmullRom      = "catmull-rom"      // x/image/draw CatmullRom, close to Lanczos3, single goroutine
+	ResamplerApproxBiLinear  = "approx-bilinear"  // x/image/draw ApproxBiLinear, fast, softer
+	ResamplerNearestNeighbor = "nearest-neighbor" // x/image/draw NearestNeighbor, fastest, blocky
+)
+
+// ImageProcessor scales image to exactly width x height, implementations differ in library and kernel
+type ImageProcessor interface {
+	Resize(img image.Image, width, height int) image.Image
+}
+
+// DefaultImageProcessor is resampler photos were always processed with
+var DefaultImageProcessor ImageProcessor = nfntProcessor{interpolation: resize.Lanczos3}
+
+// NewImageProcessor returns processor of resampler, empty resampler gives DefaultImageProcessor
+func NewImageProcessor(resampler string) (ImageProcessor, error) {
+	switch resampler {
+	case "", ResamplerLanczos3:
+		return DefaultImageProcessor, nil
+	case ResamplerCatmullRom:
+		return drawProcessor{interpolator: draw.CatmullRom}, nil
+	case ResamplerApproxBiLinear:
+		return drawProcessor{interpolator: draw.ApproxBiLinear}, nil
+	case ResamplerNearestNeighbor:
+		return drawProcessor{interpolator: draw.NearestNeighbor}, nil
+	default:
+		return nil, fmt.Errorf("unsupported resampler: %s", resampler)
+	}
+}
+
+type nfntProcessor struct {
+	interpolation resize.InterpolationFunction
+}
+
+func (p nfntProcessor) Resize(img image.Image, width, height int) image.Image {
+	return resize.Resize(uint(width), uint(height), img, p.interpolation)
+}
+
+type drawProcessor struct {
+	interpolator draw.Interpolator
+}
+
+func (p drawProcessor) Resize(img image.Image, width, height int) image.Image {
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	p.interpolator.Scale(resized, resized.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return resized
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+const (
+	benchSourceWidth  = 4000
+	benchSourceHeight = 3000
+	benchTargetWidth  = 1280
+	benchTargetHeight = 960
+)
+
+func benchmarkProcessor(b *testing.B, resampler string) {
+	processor, err := NewImageProcessor(resampler)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, benchSourceWidth, benchSourceHeight))
+	for y := 0; y < benchSourceHeight; y++ {
+		for x := 0; x < benchSourceWidth; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 0xFF})
+		}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		processor.Resize(img, benchTargetWidth, benchTargetHeight)
+	}
+}
+
+func BenchmarkResizeLanczos3(b *testing.B) {
+	benchmarkProcessor(b, ResamplerLanczos3)
+}
+
+func BenchmarkResizeCatmullRom(b *testing.B) {
+	benchmarkProcessor(b, ResamplerCatmullRom)
+}
+
+func BenchmarkResizeApproxBiLinear(b *testing.B) {
+	benchmarkProcessor(b, ResamplerApproxBiLinear)
+}
+
+func BenchmarkResizeNearestNeighbor(b *testing.B) {
+	benchmarkProcessor(b, ResamplerNearestNeighbor)
+}
+
+func TestNewImageProcessor(t *testing.T) {
+	for _, resampler := range []string{ResamplerLanczos3, ResamplerCatmullRom, ResamplerApproxBiLinear, ResamplerNearestNeighbor} {
+		processor, err := NewImageProcessor(resampler)
+		if err != nil {
+			t.Fatalf("%s: %v", resampler, err)
+		}
+
+		resized := processor.Resize(image.NewRGBA(image.Rect(0, 0, 40, 30)), 20, 15)
+		if resized.Bounds().Dx() != 20 || resized.Bounds().Dy() != 15 {
+			t.Errorf("%s: got %v, want 20x15", resampler, resized.Bounds())
+		}
+	}
+
+	if _, err := NewImageProcessor("bicubic"); err == nil {
+		t.Error("unsupported resampler accepted")
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignHMAC returns hex HMAC-SHA256 signature of parts joined by new line
+func SignHMAC(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC checks signature created by SignHMAC in constant time
+func VerifyHMAC(secret, signature string, parts ...string) bool {
+	expected, err := hex.DecodeString(SignHMAC(secret, parts...))
+	if err != nil {
+		return false
+	}
+
+	actual, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, actual)
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Thumbnail resize modes
+const (
+	ResizeModeFit   = "fit"   // scale to fit into box, letterbox the rest
+	ResizeModeFill  = "fill"  // scale to cover box, crop center
+	ResizeModeSmart = "smart" // scale to cover box, crop most detailed (highest entropy) area
+	ResizeModeLimit = "limit" // scale to fit into box, never upscale, no letterbox
+)
+
+const (
+	smartCropSteps  = 20
+	luminanceLevels = 256
+	hexColorLength  = 6
+)
+
+// IsResizeModeSupported reports whether mode can be used in variant profile
+func IsResizeModeSupported(mode string) bool {
+	switch mode {
+	case ResizeModeFit, ResizeModeFill, ResizeModeSmart, ResizeModeLimit:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseHexColor parses "#RRGGBB" color
+func ParseHexColor(hex string) (color.Color, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != hexColorLength {
+		return nil, fmt.Errorf("invalid color: %s", hex)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color: %w", err)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: math.MaxUint8}, nil
+}
+
+// Thumbnail resizes image to width x height box according to mode with DefaultImageProcessor
+func Thumbnail(img image.Image, width, height int, mode string, background color.Color) image.Image {
+	return ThumbnailWith(DefaultImageProcessor, img, width, height, mode, background)
+}
+
+// ThumbnailWith resizes image to width x height box according to mode with processor
+func ThumbnailWith(processor ImageProcessor, img image.Image, width, height int, mode string, background color.Color) image.Image {
+	srcWidth, srcHeight := img.Bounds().Dx(), img.Bounds().Dy()
+	if srcWidth == 0 || srcHeight == 0 || width <= 0 || height <= 0 {
+		return img
+	}
+
+	scaleX := float64(width) / float64(srcWidth)
+	scaleY := float64(height) / float64(srcHeight)
+
+	switch mode {
+	case ResizeModeFill, ResizeModeSmart:
+		scale := math.Max(scaleX, scaleY)
+		scaled := scaleImage(processor, img, scale)
+
+		if mode == ResizeModeSmart {
+			return crop(scaled, smartCropOrigin(scaled, width, height), width, height)
+		}
+
+		origin := image.Pt((scaled.Bounds().Dx()-width)/2, (scaled.Bounds().Dy()-height)/2)
+
+		return crop(scaled, origin, width, height)
+	case ResizeModeLimit:
+		scale := math.Min(scaleX, scaleY)
+		if scale >= 1 {
+			return img
+		}
+
+		return scaleImage(processor, img, scale)
+	default:
+		scaled := scaleImage(processor, img, math.Min(scaleX, scaleY))
+
+		canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+		offset := image.Pt((width-scaled.Bounds().Dx())/2, (height-scaled.Bounds().Dy())/2)
+		draw.Draw(canvas, scaled.Bounds().Sub(scaled.Bounds().Min).Add(offset), scaled, scaled.Bounds().Min, draw.Over)
+
+		return canvas
+	}
+}
+
+func scaleImage(processor ImageProcessor, img image.Image, scale float64) image.Image {
+	width := int(math.Max(1, math.Round(float64(img.Bounds().Dx())*scale)))
+	height := int(math.Max(1, math.Round(float64(img.Bounds().Dy())*scale)))
+
+	return processor.Resize(img, width, height)
+}
+
+func crop(img image.Image, origin image.Point, width, height int) image.Image {
+	bounds := img.Bounds()
+	rect := image.Rect(0, 0, width, height).Add(bounds.Min).Add(origin).Intersect(bounds)
+
+	cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+
+	return cropped
+}
+
+// smartCropOrigin slides crop window along overflowing axis and picks position with highest luminance entropy
+func smartCropOrigin(img image.Image, width, height int) image.Point {
+	bounds := img.Bounds()
+	overflowX := bounds.Dx() - width
+	overflowY := bounds.Dy() - height
+
+	if overflowX <= 0 && overflowY <= 0 {
+		return image.Point{}
+	}
+
+	luminance := make([]uint8, bounds.Dx()*bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			gray, ok := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			if ok {
+				luminance[y*bounds.Dx()+x] = gray.Y
+			}
+		}
+	}
+
+	overflow := max(overflowX, overflowY)
+	step := max(1, overflow/smartCropSteps)
+
+	best := image.Point{}
+	bestEntropy := -1.0
+
+	for offset := 0; offset <= overflow; offset += step {
+		origin := image.Pt(0, offset)
+		if overflowX > 0 {
+			origin = image.Pt(offset, 0)
+		}
+
+		entropy := windowEntropy(luminance, bounds.Dx(), image.Rect(0, 0, width, height).Add(origin))
+		if entropy > bestEntropy {
+			bestEntropy = entropy
+			best = origin
+		}
+	}
+
+	return best
+}
+
+func windowEntropy(luminance []uint8, stride int, window image.Rectangle) float64 {
+	histogram := make([]int, luminanceLevels)
+	total := 0
+
+	for y := window.Min.Y; y < window.Max.Y; y++ {
+		for x := window.Min.X; x < window.Max.X; x++ {
+			histogram[luminance[y*stride+x]]++
+			total++
+		}
+	}
+
+	entropy := 0.0
+
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
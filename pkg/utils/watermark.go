@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/nfnt/resize"
+)
+
+// Watermark positions
+const (
+	WatermarkTopLeft     = "top-left"
+	WatermarkTopRight    = "top-right"
+	WatermarkBottomLeft  = "bottom-left"
+	WatermarkBottomRight = "bottom-right"
+	WatermarkCenter      = "center"
+)
+
+// IsWatermarkPositionSupported reports whether position can be used in watermark profile
+func IsWatermarkPositionSupported(position string) bool {
+	switch position {
+	case WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+		return true
+	default:
+		return false
+	}
+}
+
+// Watermark draws overlay over copy of image. Overlay is scaled to scale part of image width,
+// placed at position with margin in pixels and blended with opacity 0-1
+func Watermark(img, overlay image.Image, position string, margin int, opacity, scale float64) image.Image {
+	bounds := img.Bounds()
+
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+
+	overlayWidth := uint(math.Max(1, math.Round(float64(bounds.Dx())*scale)))
+	scaled := resize.Resize(overlayWidth, 0, overlay, resize.Lanczos3)
+
+	size := scaled.Bounds().Size()
+	origin := watermarkOrigin(result.Bounds().Size(), size, position, margin)
+
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Round(math.Max(0, math.Min(1, opacity)) * math.MaxUint8))})
+	draw.DrawMask(result, image.Rectangle{Min: origin, Max: origin.Add(size)}, scaled, scaled.Bounds().Min, mask, image.Point{}, draw.Over)
+
+	return result
+}
+
+func watermarkOrigin(canvas, overlay image.Point, position string, margin int) image.Point {
+	left, top := margin, margin
+	right, bottom := canvas.X-overlay.X-margin, canvas.Y-overlay.Y-margin
+
+	switch position {
+	case WatermarkTopLeft:
+		return image.Pt(left, top)
+	case WatermarkTopRight:
+		return image.Pt(right, top)
+	case WatermarkBottomLeft:
+		return image.Pt(left, bottom)
+	case WatermarkCenter:
+		return image.Pt((canvas.X-overlay.X)/2, (canvas.Y-overlay.Y)/2)
+	default:
+		return image.Pt(right, bottom)
+	}
+}
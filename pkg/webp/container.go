@@ -0,0 +1,165 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// VP8X feature flags
+const (
+	flagICC   byte = 0x20
+	flagAlpha byte = 0x10
+	flagExif  byte = 0x08
+	flagXMP   byte = 0x04
+)
+
+const (
+	chunkHeaderSize = 8
+	vp8xPayloadSize = 10
+	vp8lHeaderSize  = 5
+	vp8lSignature   = 0x2F
+
+	vp8lDimensionBits = 14
+	vp8lDimensionMask = 1<<vp8lDimensionBits - 1
+	vp8lAlphaBit      = 28
+)
+
+var (
+	ErrInvalidWebP     = errors.New("invalid webp data")
+	ErrUnsupportedWebP = errors.New("unsupported webp layout")
+)
+
+// Metadata is embedded into extended WebP, nil fields are skipped
+type Metadata struct {
+	ICCProfile []byte
+	Exif       []byte // TIFF data, without JPEG "Exif\0\0" header
+	XMP        []byte
+}
+
+func (m Metadata) isEmpty() bool {
+	return m.ICCProfile == nil && m.Exif == nil && m.XMP == nil
+}
+
+type chunk struct {
+	fourCC string
+	data   []byte
+}
+
+// Embed converts simple lossless WebP into extended one carrying metadata:
+// VP8X header, ICCP, image data, EXIF and XMP chunks in order required by container spec
+func Embed(data []byte, metadata Metadata) ([]byte, error) {
+	if metadata.isEmpty() {
+		return data, nil
+	}
+
+	chunks, err := readChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chunks) != 1 || chunks[0].fourCC != "VP8L" {
+		return nil, ErrUnsupportedWebP
+	}
+
+	bitstream := chunks[0].data
+	if len(bitstream) < vp8lHeaderSize || bitstream[0] != vp8lSignature {
+		return nil, fmt.Errorf("%w: bad VP8L header", ErrInvalidWebP)
+	}
+
+	header := binary.LittleEndian.Uint32(bitstream[1:])
+	width := header&vp8lDimensionMask + 1
+	height := header>>vp8lDimensionBits&vp8lDimensionMask + 1
+
+	var flags byte
+	if header>>vp8lAlphaBit&1 == 1 {
+		flags |= flagAlpha
+	}
+
+	extended := make([]chunk, 0, 5)
+	extended = append(extended, chunk{fourCC: "VP8X"}) // filled when flags are known
+
+	if metadata.ICCProfile != nil {
+		flags |= flagICC
+		extended = append(extended, chunk{fourCC: "ICCP", data: metadata.ICCProfile})
+	}
+
+	extended = append(extended, chunks[0])
+
+	if metadata.Exif != nil {
+		flags |= flagExif
+		extended = append(extended, chunk{fourCC: "EXIF", data: metadata.Exif})
+	}
+
+	if metadata.XMP != nil {
+		flags |= flagXMP
+		extended = append(extended, chunk{fourCC: "XMP ", data: metadata.XMP})
+	}
+
+	vp8x := make([]byte, vp8xPayloadSize)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], width-1)
+	putUint24(vp8x[7:], height-1)
+	extended[0].data = vp8x
+
+	return writeChunks(extended), nil
+}
+
+func readChunks(data []byte) ([]chunk, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidWebP
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if size+chunkHeaderSize > len(data) {
+		return nil, fmt.Errorf("%w: truncated", ErrInvalidWebP)
+	}
+
+	chunks := make([]chunk, 0)
+	pos := 12
+
+	for pos+chunkHeaderSize <= size+chunkHeaderSize {
+		chunkSize := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if pos+chunkHeaderSize+chunkSize > len(data) {
+			return nil, fmt.Errorf("%w: truncated chunk", ErrInvalidWebP)
+		}
+
+		chunks = append(chunks, chunk{
+			fourCC: string(data[pos : pos+4]),
+			data:   data[pos+chunkHeaderSize : pos+chunkHeaderSize+chunkSize],
+		})
+		pos += chunkHeaderSize + chunkSize + chunkSize%2
+	}
+
+	return chunks, nil
+}
+
+func writeChunks(chunks []chunk) []byte {
+	body := new(bytes.Buffer)
+	body.WriteString("WEBP")
+
+	for _, c := range chunks {
+		body.WriteString(c.fourCC)
+		_ = binary.Write(body, binary.LittleEndian, uint32(len(c.data)))
+		body.Write(c.data)
+
+		// chunks are padded to even size, padding isn't counted in chunk size
+		if len(c.data)%2 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
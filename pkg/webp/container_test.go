@@ -0,0 +1,211 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/webp"
+)
+
+const (
+	testWidth  = 5
+	testHeight = 3
+)
+
+func encodeLossless(t *testing.T, opaque bool) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, testWidth, testHeight))
+	for y := 0; y < testHeight; y++ {
+		for x := 0; x < testWidth; x++ {
+			alpha := uint8(0xFF)
+			if !opaque {
+				alpha = uint8(x * 50)
+			}
+
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 40), G: uint8(y * 80), B: 0x60, A: alpha})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := nativewebp.Encode(buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func fourCCs(chunks []chunk) []string {
+	result := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		result = append(result, c.fourCC)
+	}
+
+	return result
+}
+
+func TestEmbedRoundTrip(t *testing.T) {
+	source := encodeLossless(t, true)
+
+	// Odd sizes check chunk padding
+	metadata := Metadata{
+		ICCProfile: bytes.Repeat([]byte{0x01}, 131),
+		Exif:       []byte("II*\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00"),
+		XMP:        []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"></x:xmpmeta>"),
+	}
+
+	extended, err := Embed(source, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := binary.LittleEndian.Uint32(extended[4:]); int(size)+chunkHeaderSize != len(extended) {
+		t.Errorf("RIFF size %d, file size %d", size, len(extended))
+	}
+
+	chunks, err := readChunks(extended)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := fourCCs(chunks), []string{"VP8X", "ICCP", "VP8L", "EXIF", "XMP "}; !slices.Equal(got, want) {
+		t.Fatalf("chunks %v, want %v", got, want)
+	}
+
+	vp8x := chunks[0].data
+	if flags := vp8x[0]; flags != flagICC|flagExif|flagXMP {
+		t.Errorf("flags %08b", flags)
+	}
+
+	width := uint32(vp8x[4]) | uint32(vp8x[5])<<8 | uint32(vp8x[6])<<16
+	height := uint32(vp8x[7]) | uint32(vp8x[8])<<8 | uint32(vp8x[9])<<16
+
+	if width+1 != testWidth || height+1 != testHeight {
+		t.Errorf("canvas %dx%d, want %dx%d", width+1, height+1, testWidth, testHeight)
+	}
+
+	for i, want := range [][]byte{metadata.ICCProfile, nil, metadata.Exif, metadata.XMP} {
+		if want != nil && !bytes.Equal(chunks[i+1].data, want) {
+			t.Errorf("%s chunk differs", chunks[i+1].fourCC)
+		}
+	}
+
+	sourceChunks, err := readChunks(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(chunks[2].data, sourceChunks[0].data) {
+		t.Error("VP8L bitstream changed")
+	}
+
+	img, err := webp.Decode(bytes.NewReader(extended))
+	if err != nil {
+		t.Fatalf("webp.Decode: %v", err)
+	}
+
+	if img.Bounds().Dx() != testWidth || img.Bounds().Dy() != testHeight {
+		t.Errorf("decoded %v", img.Bounds())
+	}
+}
+
+// x/image/webp expects ALPH chunk whenever alpha flag is set, so alpha of VP8L is checked by flags only
+func TestEmbedAlphaFlag(t *testing.T) {
+	extended, err := Embed(encodeLossless(t, false), Metadata{Exif: []byte("II*\x00")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks, err := readChunks(extended)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flags := chunks[0].data[0]; flags != flagAlpha|flagExif {
+		t.Errorf("flags %08b", flags)
+	}
+}
+
+func TestEmbedPartialMetadata(t *testing.T) {
+	extended, err := Embed(encodeLossless(t, true), Metadata{XMP: []byte("<x/>")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks, err := readChunks(extended)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := fourCCs(chunks), []string{"VP8X", "VP8L", "XMP "}; !slices.Equal(got, want) {
+		t.Fatalf("chunks %v, want %v", got, want)
+	}
+
+	if flags := chunks[0].data[0]; flags&(flagICC|flagExif) != 0 || flags&flagXMP == 0 {
+		t.Errorf("flags %08b", flags)
+	}
+}
+
+func TestEmbedEmptyMetadata(t *testing.T) {
+	source := encodeLossless(t, true)
+
+	extended, err := Embed(source, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(extended, source) {
+		t.Error("WebP changed without metadata")
+	}
+}
+
+func TestEmbedMalformed(t *testing.T) {
+	source := encodeLossless(t, true)
+	metadata := Metadata{Exif: []byte("II*\x00")}
+
+	extended, err := Embed(source, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Embed(extended, metadata); !errors.Is(err, ErrUnsupportedWebP) {
+		t.Errorf("extended: got %v, want %v", err, ErrUnsupportedWebP)
+	}
+
+	lossy := writeChunks([]chunk{{fourCC: "VP8 ", data: make([]byte, 10)}})
+	if _, err := Embed(lossy, metadata); !errors.Is(err, ErrUnsupportedWebP) {
+		t.Errorf("lossy: got %v, want %v", err, ErrUnsupportedWebP)
+	}
+
+	oversizedRIFF := bytes.Clone(source)
+	binary.LittleEndian.PutUint32(oversizedRIFF[4:], uint32(len(source)))
+
+	oversizedChunk := bytes.Clone(source)
+	binary.LittleEndian.PutUint32(oversizedChunk[16:], 0xFFFFFFF0)
+
+	badSignature := bytes.Clone(source)
+	badSignature[20] = 0
+
+	cases := map[string][]byte{
+		"empty":           nil,
+		"not RIFF":        append([]byte("RIFX"), source[4:]...),
+		"not WEBP":        append(append([]byte(nil), source[:8]...), append([]byte("WAVE"), source[12:]...)...),
+		"truncated":       source[:len(source)-4],
+		"oversized RIFF":  oversizedRIFF,
+		"oversized chunk": oversizedChunk,
+		"bad signature":   badSignature,
+		"short VP8L":      writeChunks([]chunk{{fourCC: "VP8L", data: []byte{vp8lSignature, 0}}}),
+	}
+
+	for name, data := range cases {
+		if _, err := Embed(data, metadata); !errors.Is(err, ErrInvalidWebP) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidWebP)
+		}
+	}
+}
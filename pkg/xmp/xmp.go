@@ -0,0 +1,192 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"test-task-photo-booth/pkg/exif"
+)
+
+// Namespaces of written properties
+const (
+	nsRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC        = "http://purl.org/dc/elements/1.1/"
+	nsPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+)
+
+var (
+	ErrNoXMP      = errors.New("no xmp data")
+	ErrInvalidXMP = errors.New("invalid xmp data")
+)
+
+var xmpHeader = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+// Packet is attribution subset of XMP: dc:creator, dc:rights and photoshop:Credit
+type Packet struct {
+	Creator string
+	Rights  string
+	Credit  string
+}
+
+// Bytes serializes packet, empty properties are omitted
+func (p Packet) Bytes() []byte {
+	buf := new(bytes.Buffer)
+
+	buf.WriteString(`<?xpacket begin="` + "\uFEFF" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>` + "\n")
+	buf.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	buf.WriteString(` <rdf:RDF xmlns:rdf="` + nsRDF + `">` + "\n")
+	buf.WriteString(`  <rdf:Description rdf:about=""` +
+		` xmlns:dc="` + nsDC + `"` +
+		` xmlns:photoshop="` + nsPhotoshop + `">` + "\n")
+
+	if p.Creator != "" {
+		buf.WriteString(`   <dc:creator><rdf:Seq><rdf:li>` + escape(p.Creator) + `</rdf:li></rdf:Seq></dc:creator>` + "\n")
+	}
+
+	if p.Rights != "" {
+		buf.WriteString(`   <dc:rights><rdf:Alt><rdf:li xml:lang="x-default">` + escape(p.Rights) + `</rdf:li></rdf:Alt></dc:rights>` + "\n")
+	}
+
+	if p.Credit != "" {
+		buf.WriteString(`   <photoshop:Credit>` + escape(p.Credit) + `</photoshop:Credit>` + "\n")
+	}
+
+	buf.WriteString("  </rdf:Description>\n </rdf:RDF>\n</x:xmpmeta>\n")
+	buf.WriteString(`<?xpacket end="w"?>`)
+
+	return buf.Bytes()
+}
+
+func escape(value string) string {
+	buf := new(strings.Builder)
+	_ = xml.EscapeText(buf, []byte(value))
+
+	return buf.String()
+}
+
+// description is rdf:Description, properties may be written as elements or as attributes.
+// Namespace of parent>child path applies to its last element, so rdf lists are nested structs
+type description struct {
+	Creator    rdfSeq `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Rights     rdfAlt `xml:"http://purl.org/dc/elements/1.1/ rights"`
+	Credit     string `xml:"http://ns.adobe.com/photoshop/1.0/ Credit"`
+	CreditAttr string `xml:"http://ns.adobe.com/photoshop/1.0/ Credit,attr"`
+}
+
+type rdfSeq struct {
+	Items []string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# Seq>li"`
+}
+
+type rdfAlt struct {
+	Items []string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# Alt>li"`
+}
+
+type xmpMeta struct {
+	Descriptions []description `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF>Description"`
+}
+
+// Parse reads attribution properties of XMP packet, properties are merged from all rdf:Description elements
+func Parse(data []byte) (Packet, error) {
+	var meta xmpMeta
+	if err := xml.Unmarshal(bytes.TrimSpace(stripPacketWrapper(data)), &meta); err != nil {
+		return Packet{}, fmt.Errorf("%w: %w", ErrInvalidXMP, err)
+	}
+
+	var packet Packet
+
+	for _, d := range meta.Descriptions {
+		if len(d.Creator.Items) > 0 && packet.Creator == "" {
+			packet.Creator = strings.Join(d.Creator.Items, "; ")
+		}
+
+		if len(d.Rights.Items) > 0 && packet.Rights == "" {
+			packet.Rights = d.Rights.Items[0]
+		}
+
+		packet.Credit = firstNonEmpty(packet.Credit, d.Credit, d.CreditAttr)
+	}
+
+	return packet, nil
+}
+
+// stripPacketWrapper drops xpacket processing instructions, encoding/xml accepts single root element only
+func stripPacketWrapper(data []byte) []byte {
+	start := bytes.Index(data, []byte("<x:xmpmeta"))
+	end := bytes.LastIndex(data, []byte("</x:xmpmeta>"))
+
+	if start < 0 || end < start {
+		return data
+	}
+
+	return data[start : end+len("</x:xmpmeta>")]
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// ExtractJPEG returns XMP packet of JPEG APP1 segment
+func ExtractJPEG(data []byte) ([]byte, error) {
+	segments, _, err := exif.ReadSegments(data)
+	if err != nil {
+		return nil, fmt.Errorf("exif.ReadSegments() failed: %w", err)
+	}
+
+	for _, segment := range segments {
+		if isXMPSegment(segment) {
+			return segment.Data[len(xmpHeader):], nil
+		}
+	}
+
+	return nil, ErrNoXMP
+}
+
+// ReplaceJPEG replaces XMP APP1 segment of JPEG with packet, it goes after JFIF APP0 and EXIF APP1
+func ReplaceJPEG(data, packet []byte) ([]byte, error) {
+	segments, scan, err := exif.ReadSegments(data)
+	if err != nil {
+		return nil, fmt.Errorf("exif.ReadSegments() failed: %w", err)
+	}
+
+	xmpSegment := exif.Segment{Marker: exif.MarkerAPP1, Data: append(append([]byte(nil), xmpHeader...), packet...)}
+
+	result := make([]exif.Segment, 0, len(segments)+1)
+	inserted := false
+
+	for _, segment := range segments {
+		if isXMPSegment(segment) {
+			continue
+		}
+
+		if !inserted && segment.Marker != exif.MarkerAPP0 && segment.Marker != exif.MarkerAPP1 {
+			result = append(result, xmpSegment)
+			inserted = true
+		}
+
+		result = append(result, segment)
+	}
+
+	if !inserted {
+		result = append(result, xmpSegment)
+	}
+
+	jpeg, err := exif.WriteSegments(result, scan)
+	if err != nil {
+		return nil, fmt.Errorf("exif.WriteSegments() failed: %w", err)
+	}
+
+	return jpeg, nil
+}
+
+func isXMPSegment(segment exif.Segment) bool {
+	return segment.Marker == exif.MarkerAPP1 && bytes.HasPrefix(segment.Data, xmpHeader)
+}
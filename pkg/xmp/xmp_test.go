@@ -0,0 +1,170 @@
+package xmp
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"test-task-photo-booth/pkg/exif"
+)
+
+func sampleJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestJPEGRoundTrip(t *testing.T) {
+	packets := []Packet{
+		{Creator: "Jane Doe", Rights: "© 2024 Booth & Co", Credit: "Booth <Studio>"},
+		{Creator: `"Quoted" name`},
+		{Rights: "All rights reserved"},
+		{},
+	}
+
+	for _, packet := range packets {
+		withXMP, err := ReplaceJPEG(sampleJPEG(t), packet.Bytes())
+		if err != nil {
+			t.Fatalf("%+v: ReplaceJPEG: %v", packet, err)
+		}
+
+		extracted, err := ExtractJPEG(withXMP)
+		if err != nil {
+			t.Fatalf("%+v: ExtractJPEG: %v", packet, err)
+		}
+
+		parsed, err := Parse(extracted)
+		if err != nil {
+			t.Fatalf("%+v: Parse: %v", packet, err)
+		}
+
+		if parsed != packet {
+			t.Errorf("got %+v, want %+v", parsed, packet)
+		}
+
+		if _, err := jpeg.Decode(bytes.NewReader(withXMP)); err != nil {
+			t.Errorf("%+v: jpeg.Decode: %v", packet, err)
+		}
+	}
+}
+
+func TestBytesOmitsEmptyProperties(t *testing.T) {
+	data := string(Packet{Credit: "Booth"}.Bytes())
+
+	if strings.Contains(data, "dc:creator") || strings.Contains(data, "dc:rights") || !strings.Contains(data, "photoshop:Credit") {
+		t.Errorf("unexpected packet:\n%s", data)
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := `<?xpacket begin="` + "\uFEFF" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/" photoshop:Credit=" Agency "/>
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li><rdf:li>John Doe</rdf:li></rdf:Seq></dc:creator>
+   <dc:rights><rdf:Alt><rdf:li xml:lang="x-default">CC BY 4.0</rdf:li><rdf:li xml:lang="de">CC BY 4.0 DE</rdf:li></rdf:Alt></dc:rights>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:creator><rdf:Seq><rdf:li>Ignored</rdf:li></rdf:Seq></dc:creator>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+	packet, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Packet{Creator: "Jane Doe; John Doe", Rights: "CC BY 4.0", Credit: "Agency"}
+	if packet != want {
+		t.Errorf("got %+v, want %+v", packet, want)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	cases := map[string]string{
+		"empty":        "",
+		"not xml":      "not xmp at all",
+		"unclosed":     `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF>`,
+		"mismatched":   `<x:xmpmeta xmlns:x="adobe:ns:meta/"></rdf:RDF></x:xmpmeta>`,
+		"wrapper only": `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?><?xpacket end="w"?>`,
+	}
+
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); !errors.Is(err, ErrInvalidXMP) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidXMP)
+		}
+	}
+}
+
+func TestReplaceJPEG(t *testing.T) {
+	withExif, err := exif.ReplaceJPEG(sampleJPEG(t), []byte("II*\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withXMP, err := ReplaceJPEG(withExif, Packet{Creator: "First"}.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing keeps single XMP segment
+	withXMP, err = ReplaceJPEG(withXMP, Packet{Creator: "Second"}.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segments, _, err := exif.ReadSegments(withXMP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exifIndex, xmpIndex, xmpCount := -1, -1, 0
+
+	for i, segment := range segments {
+		switch {
+		case isXMPSegment(segment):
+			xmpIndex = i
+			xmpCount++
+		case segment.Marker == exif.MarkerAPP1:
+			exifIndex = i
+		}
+	}
+
+	if xmpCount != 1 || exifIndex < 0 || xmpIndex < exifIndex {
+		t.Errorf("segments: exif %d, xmp %d, xmp count %d", exifIndex, xmpIndex, xmpCount)
+	}
+
+	extracted, err := ExtractJPEG(withXMP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if packet, err := Parse(extracted); err != nil || packet.Creator != "Second" {
+		t.Errorf("got %+v, %v", packet, err)
+	}
+}
+
+func TestExtractJPEGMalformed(t *testing.T) {
+	if _, err := ExtractJPEG(sampleJPEG(t)); !errors.Is(err, ErrNoXMP) {
+		t.Errorf("plain: got %v, want %v", err, ErrNoXMP)
+	}
+
+	if _, err := ExtractJPEG([]byte("not jpeg")); !errors.Is(err, exif.ErrInvalidJPEG) {
+		t.Errorf("not jpeg: got %v, want %v", err, exif.ErrInvalidJPEG)
+	}
+
+	if _, err := ReplaceJPEG(sampleJPEG(t), make([]byte, exif.MaxSegmentSize)); !errors.Is(err, exif.ErrSegmentTooLarge) {
+		t.Errorf("oversized packet: got %v, want %v", err, exif.ErrSegmentTooLarge)
+	}
+}
@@ -18,6 +18,7 @@ type Configs struct {
 	ViperConfigPath string `env:"SERVICE_CONFIG, required"`
 	PostgresConf    PostgresDBConf
 	RabbitMQConf    RabbitMQConf
+	SecretsConf     SecretsConf
 }
 
 // PostgresDBConf creates config for db connection
@@ -38,6 +39,12 @@ type RabbitMQConf struct {
 	Password string `env:"SERVICE_RMQPASSWORD, required"`
 }
 
+// SecretsConf creates config for signing urls
+type SecretsConf struct {
+	UploadSecret    string `env:"SERVICE_UPLOADSECRET"`    // required by producer for direct uploads
+	TransformSecret string `env:"SERVICE_TRANSFORMSECRET"` // only allowlisted transformations are served when empty
+}
+
 func GetConfig() (Configs, error) {
 	var cfg Configs
 
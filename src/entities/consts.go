@@ -20,7 +20,59 @@ const (
 	ServicesVersion = "services.version"
 )
 
+// Direct uploads dir, producer saves uploads there and consumer reads finalized ones
+const (
+	ConfigUploadsDir  = "uploads.dir"
+	DefaultUploadsDir = "./uploads"
+)
+
 // RabbitMq
 const (
-	PhotosQueue = "photos"
+	PhotosQueue    = "photos"
+	ReprocessQueue = "reprocess"
+)
+
+// Photo queue message types
+const (
+	PhotoMessageUpload    = "upload"
+	PhotoMessageImport    = "import"
+	PhotoMessageStrip     = "strip"
+	PhotoMessageBoomerang = "boomerang"
+	PhotoMessageReprocess = "reprocess"
+)
+
+// Photo quality flags
+const (
+	QualityFlagBlurry       = "blurry"
+	QualityFlagUnderexposed = "underexposed"
+	QualityFlagOverexposed  = "overexposed"
+	QualityFlagEmpty        = "empty"
+)
+
+// Reprocess job statuses
+const (
+	ReprocessStatusRunning = "running"
+	ReprocessStatusDone    = "done"
+)
+
+// Photos listing sort keys
+const (
+	PhotoSortCapturedAt  = "capturedAt"
+	PhotoSortCameraMake  = "cameraMake"
+	PhotoSortCameraModel = "cameraModel"
+	PhotoSortISO         = "iso"
+	PhotoSortFocalLength = "focalLength"
+)
+
+// Photo transformation output formats
+const (
+	TransformFormatJPEG = "jpeg"
+	TransformFormatPNG  = "png"
+	TransformFormatWebP = "webp"
+)
+
+// Variant profile output formats
+const (
+	VariantFormatJPEG = "jpeg"
+	VariantFormatWebP = "webp"
 )
@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	ErrorOsCloseFailed = errors.New("os.Close failed")
+	ErrNoHotFolderDirs = errors.New("no hot folder dirs configured")
 )
@@ -0,0 +1,39 @@
+package customErrors
+
+import "errors"
+
+var (
+	ErrInvalidDataURI   = errors.New("invalid data URI")
+	ErrMimeTypeMismatch = errors.New("declared mime type doesn't match photo content")
+
+	ErrUnsupportedPhotoType = errors.New("unsupported photo type")
+	ErrPhotoTooLarge        = errors.New("photo exceeds max allowed size")
+	ErrRemoteURLNotAllowed  = errors.New("remote url is not allowed")
+	ErrRemoteFetchFailed    = errors.New("remote photo fetch failed")
+	ErrTooManyRedirects     = errors.New("too many redirects")
+
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUploadExpired    = errors.New("upload url expired")
+	ErrUploadNotFound   = errors.New("upload not found")
+	ErrUploadFinalized  = errors.New("upload already finalized")
+
+	ErrInvalidTransform = errors.New("invalid transform parameters")
+	ErrCacheMiss        = errors.New("cache miss")
+
+	ErrPhotoNotFound    = errors.New("photo not found")
+	ErrNoPerceptualHash = errors.New("photo has no perceptual hash")
+	ErrPhotoDeleted     = errors.New("photo is deleted")
+
+	ErrInvalidStrip     = errors.New("invalid strip parameters")
+	ErrInvalidBoomerang = errors.New("invalid boomerang parameters")
+	ErrInvalidTemplate  = errors.New("invalid template")
+	ErrInvalidReprocess = errors.New("invalid reprocess selection")
+	ErrInvalidBurst     = errors.New("invalid burst")
+
+	ErrUnsupportedVariantFormat = errors.New("unsupported variant format")
+
+	ErrBackgroundNotFound = errors.New("background not found")
+	ErrTemplateNotFound   = errors.New("template not found")
+
+	ErrReprocessJobNotFound = errors.New("reprocess job not found")
+)
@@ -0,0 +1,8 @@
+package dtos
+
+// Attribution is author information embedded into JPEG outputs as EXIF and XMP
+type Attribution struct {
+	Creator   string `json:"creator,omitempty" mapstructure:"creator"`     // EXIF Artist, XMP dc:creator
+	Copyright string `json:"copyright,omitempty" mapstructure:"copyright"` // EXIF Copyright, XMP dc:rights
+	Credit    string `json:"credit,omitempty" mapstructure:"credit"`       // XMP photoshop:Credit
+}
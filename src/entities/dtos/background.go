@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+// Background is image of backgrounds library used to replace chroma key backdrop
+type Background struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	MimeType  string    `json:"mimeType"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Data      string    `json:"data,omitempty"` // Stored in b64, omitted in listing
+	CreatedAt time.Time `json:"createdAt"`
+	IsDeleted bool      `json:"-"` // hidden from library, photos using background still render with it
+}
@@ -0,0 +1,11 @@
+package dtos
+
+// Boomerang is looping forward-then-reverse animated GIF composed by consumer from stored photos or animated GIF upload
+type Boomerang struct {
+	PhotoIDs []string `json:"photoIds,omitempty"` // frames in order
+	Data     string   `json:"data,omitempty"`     // animated GIF, b64 or data URI, used when PhotoIDs are empty
+	DelayMs  int      `json:"delayMs,omitempty"`  // boomerang.delayMs config by default
+	Width    int      `json:"width,omitempty"`    // boomerang.width config by default
+
+	Options ProcessingOptions `json:"options"` // applied to variants of boomerang
+}
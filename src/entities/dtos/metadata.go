@@ -0,0 +1,33 @@
+package dtos
+
+import "time"
+
+// PhotoMetadata is camera metadata parsed from original photo EXIF, optional fields are nil when not recorded
+type PhotoMetadata struct {
+	PhotoID      string     `json:"photoId"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	CapturedAt   *time.Time `json:"capturedAt,omitempty"`
+	CameraMake   *string    `json:"cameraMake,omitempty"`
+	CameraModel  *string    `json:"cameraModel,omitempty"`
+	Lens         *string    `json:"lens,omitempty"`
+	ExposureTime *string    `json:"exposureTime,omitempty"` // e.g. "1/125"
+	FNumber      *float64   `json:"fNumber,omitempty"`
+	ISO          *int       `json:"iso,omitempty"`
+	FocalLength  *float64   `json:"focalLength,omitempty"` // mm
+	GPSLatitude  *float64   `json:"gpsLatitude,omitempty"`
+	GPSLongitude *float64   `json:"gpsLongitude,omitempty"`
+	ColorSpace   *string    `json:"colorSpace,omitempty"` // ICC profile description, e.g. "Adobe RGB (1998)"
+}
+
+// PhotoFilter filters and sorts photos listing by metadata
+type PhotoFilter struct {
+	CameraMake   string
+	CameraModel  string
+	CapturedFrom *time.Time
+	CapturedTo   *time.Time
+	HasGPS       *bool
+	HideFlagged  bool // hides photos with quality flags
+	SortBy       string
+	SortDesc     bool
+}
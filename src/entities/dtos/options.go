@@ -0,0 +1,32 @@
+package dtos
+
+// ProcessingOptions are per-photo processing settings, set on upload and kept for reprocessing
+type ProcessingOptions struct {
+	NoWatermark bool              `json:"noWatermark,omitempty"` // variants are rendered without watermark
+	Filters     []string          `json:"filters,omitempty"`     // applied to variants in order, "name" or "name:strength"
+	Event       string            `json:"event,omitempty"`       // booth event, its settings apply unless set on upload
+	ChromaKey   *ChromaKeyOptions `json:"chromaKey,omitempty"`   // replaces green screen, event chroma key by default
+	Template    string            `json:"template,omitempty"`    // template id photo is framed into, event template by default
+	Caption     *CaptionOptions   `json:"caption,omitempty"`     // text printed on photo, template caption by default
+}
+
+// ChromaKeyOptions select background from library, empty fields are taken from chromaKey config
+type ChromaKeyOptions struct {
+	Background string   `json:"background" mapstructure:"background"`         // background id
+	KeyColor   string   `json:"keyColor,omitempty" mapstructure:"keyColor"`   // "#RRGGBB"
+	Tolerance  *float64 `json:"tolerance,omitempty" mapstructure:"tolerance"` // 0-1
+	Softness   *float64 `json:"softness,omitempty" mapstructure:"softness"`   // 0-1
+}
+
+// CaptionOptions describe text printed on photo, empty fields are taken from captions config.
+// Text may hold {date} (capture date, processing date if unknown) and {event} placeholders
+type CaptionOptions struct {
+	Text         string  `json:"text"`
+	Font         string  `json:"font,omitempty"`         // "regular", "basic" or name from captions.fonts config
+	Size         float64 `json:"size,omitempty"`         // line height as part of photo height, 0-1
+	Color        string  `json:"color,omitempty"`        // "#RRGGBB"
+	OutlineColor string  `json:"outlineColor,omitempty"` // "#RRGGBB"
+	Outline      float64 `json:"outline,omitempty"`      // outline width as part of size, 0-1
+	Position     string  `json:"position,omitempty"`     // top, bottom, top-left, top-right, bottom-left, bottom-right or center
+	Region       string  `json:"region,omitempty"`       // template region caption is placed in, "caption" by default
+}
@@ -1,9 +1,27 @@
 package dtos
 
+import "time"
+
 type Photo struct {
-	ID        string `json:"id"`
-	Data      string `json:"data,omitempty"` // Stored in b64
-	IsDeleted bool   `json:"isDeleted"`
+	ID        string            `json:"id"`
+	Data      string            `json:"data,omitempty"` // Stored in b64
+	IsDeleted bool              `json:"isDeleted"`
+	Options   ProcessingOptions `json:"options"`
+
+	RemovedExifTags []string `json:"removedExifTags,omitempty"` // EXIF tags stripped from original
+	PerceptualHash  string   `json:"perceptualHash,omitempty"`  // dHash in hex
+	DuplicateOf     string   `json:"duplicateOf,omitempty"`     // near-duplicate flagged at ingest
+	BlurHash        string   `json:"blurhash,omitempty"`        // placeholder shown while photo loads
+	Palette         []string `json:"palette,omitempty"`         // dominant colors "#rrggbb", most frequent first
+
+	QualityScores *QualityScores `json:"qualityScores,omitempty"`
+	QualityFlags  []string       `json:"qualityFlags,omitempty"` // blurry, underexposed, overexposed or empty
+}
+
+// SimilarPhoto is photo found by perceptual hash within Hamming distance
+type SimilarPhoto struct {
+	ID       string `json:"id"`
+	Distance int    `json:"distance"`
 }
 
 type PhotoDB struct {
@@ -13,4 +31,36 @@ type PhotoDB struct {
 	Data50     string `json:"data50"`     // Stored in b64
 	Data25     string `json:"data25"`     // Stored in b64
 	IsDeleted  bool   `json:"isDeleted"`
+
+	RemovedExifTags []string          `json:"removedExifTags"`
+	Metadata        *PhotoMetadata    `json:"metadata,omitempty"`
+	Variants        []PhotoVariant    `json:"variants,omitempty"` // generated by variant profiles
+	PerceptualHash  *uint64           `json:"perceptualHash,omitempty"`
+	DuplicateOf     string            `json:"duplicateOf,omitempty"`
+	BlurHash        string            `json:"blurhash"`
+	Palette         []string          `json:"palette"`
+	Options         ProcessingOptions `json:"options"`
+	QualityScores   *QualityScores    `json:"qualityScores,omitempty"`
+	QualityFlags    []string          `json:"qualityFlags"`
+}
+
+// PhotoVersion tells whether images derived from photo are still current without loading photo data
+type PhotoVersion struct {
+	UpdatedAt time.Time
+	IsDeleted bool
+	Options   ProcessingOptions // library items and event photo is rendered with
+}
+
+// PhotoMessage is photo processing job, passed from producer to consumer via queue
+type PhotoMessage struct {
+	Type      string     `json:"type"`
+	Data      string     `json:"data,omitempty"` // Stored in b64
+	SourceURL string     `json:"sourceUrl,omitempty"`
+	Strip     *Strip     `json:"strip,omitempty"`
+	Boomerang *Boomerang `json:"boomerang,omitempty"`
+	PhotoID   string     `json:"photoId,omitempty"`  // stored photo to reprocess
+	JobID     string     `json:"jobId,omitempty"`    // reprocess job counting progress
+	UploadID  string     `json:"uploadId,omitempty"` // finalized direct upload, read by consumer from upload storage
+
+	Options ProcessingOptions `json:"options"`
 }
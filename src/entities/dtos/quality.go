@@ -0,0 +1,23 @@
+package dtos
+
+// QualityScores are measured on unprocessed photo at ingest
+type QualityScores struct {
+	Sharpness         float64 `json:"sharpness"`         // variance of Laplacian, higher is sharper
+	Luminance         float64 `json:"luminance"`         // mean luminance 0-1
+	ShadowClipping    float64 `json:"shadowClipping"`    // part of pixels clipped to black 0-1
+	HighlightClipping float64 `json:"highlightClipping"` // part of pixels clipped to white 0-1
+	Contrast          float64 `json:"contrast"`          // luminance standard deviation 0-1
+}
+
+// BurstPhoto is photo of burst with its quality scores
+type BurstPhoto struct {
+	ID            string         `json:"id"`
+	QualityScores *QualityScores `json:"qualityScores"`
+	QualityFlags  []string       `json:"qualityFlags"`
+}
+
+// BestOfBurst is best photo of burst, Photos are ranked best first
+type BestOfBurst struct {
+	ID     string       `json:"id"`
+	Photos []BurstPhoto `json:"photos"`
+}
@@ -0,0 +1,21 @@
+package dtos
+
+import "time"
+
+// Reprocess selects stored photos to regenerate from originals: by ids, by metadata filter or all of them
+type Reprocess struct {
+	PhotoIDs []string
+	Filter   *PhotoFilter
+	All      bool
+}
+
+// ReprocessJob tracks regeneration progress, every photo is counted once either as processed or failed
+type ReprocessJob struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Total     int       `json:"total"`
+	Processed int       `json:"processed"`
+	Failed    int       `json:"failed"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+// Strip is photo strip or collage composed by consumer from already stored photos
+type Strip struct {
+	PhotoIDs   []string `json:"photoIds"`
+	Layout     string   `json:"layout"`               // strip, grid or 2x2
+	Spacing    int      `json:"spacing"`              // pixels between and around photos
+	Background string   `json:"background,omitempty"` // "#RRGGBB", white by default
+	Frame      string   `json:"frame,omitempty"`      // PNG drawn over whole strip, b64 or data URI
+	Header     int      `json:"header,omitempty"`     // pixels above photos, for branding of frame
+	Footer     int      `json:"footer,omitempty"`     // pixels below photos, for branding of frame
+	CellWidth  int      `json:"cellWidth,omitempty"`  // strips.cellWidth config by default
+	CellHeight int      `json:"cellHeight,omitempty"` // strips.cellHeight config by default
+
+	Options ProcessingOptions `json:"options"` // applied to composed strip
+}
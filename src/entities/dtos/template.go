@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+// Template is branded PNG frame, photo is composited into its placement region under the frame
+type Template struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Width     int              `json:"width"`
+	Height    int              `json:"height"`
+	Regions   []TemplateRegion `json:"regions"`
+	Caption   *CaptionOptions  `json:"caption,omitempty"` // printed on photos framed into template
+	Data      string           `json:"data,omitempty"`    // PNG stored in b64, omitted in listing
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+	IsDeleted bool             `json:"-"` // hidden from library, photos framed into template still render with it
+}
+
+// TemplateRegion is named rectangle of template in its pixels
+type TemplateRegion struct {
+	Name   string `json:"name"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
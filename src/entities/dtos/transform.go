@@ -0,0 +1,18 @@
+package dtos
+
+// TransformParams is parameter set of on-the-fly photo transformation
+type TransformParams struct {
+	Width   int    `mapstructure:"w"`      // 0 keeps aspect ratio by height
+	Height  int    `mapstructure:"h"`      // 0 keeps aspect ratio by width
+	Fit     string `mapstructure:"fit"`    // fit, fill, smart or limit
+	Format  string `mapstructure:"format"` // jpeg, png or webp, negotiated by Accept when empty
+	Quality int    `mapstructure:"q"`      // JPEG quality 1-100
+
+	Filters []string `mapstructure:"filters"` // applied after filters requested at upload
+}
+
+// DerivedImage is encoded image served as is: photo rendered by transformation or stored variant
+type DerivedImage struct {
+	MimeType string
+	Data     []byte
+}
@@ -0,0 +1,11 @@
+package dtos
+
+import "time"
+
+// Upload is slot for direct photo upload via presigned url
+type Upload struct {
+	ID          string    `json:"id"`
+	UploadURL   string    `json:"uploadUrl"`   // PUT raw photo bytes
+	FinalizeURL string    `json:"finalizeUrl"` // POST after upload to enqueue processing
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,41 @@
+package dtos
+
+// PhotoVariant is photo rendition generated by variant profile
+type PhotoVariant struct {
+	PhotoID  string `json:"photoId"`
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Quality  int    `json:"quality"`        // achieved JPEG quality, 0 for lossless WebP
+	Size     int    `json:"size"`           // bytes
+	Data     string `json:"data,omitempty"` // Stored in b64
+}
+
+// VariantProfile describes variant generated for every photo, loaded from config
+type VariantProfile struct {
+	Name       string `mapstructure:"name"`
+	Width      int    `mapstructure:"width"`
+	Height     int    `mapstructure:"height"`
+	Mode       string `mapstructure:"mode"`       // fit, fill, smart or limit
+	Background string `mapstructure:"background"` // letterbox color for fit mode, e.g. "#FFFFFF"
+	Quality    int    `mapstructure:"quality"`    // JPEG quality 1-100, max quality when MaxBytes is set
+	MaxBytes   int    `mapstructure:"maxBytes"`   // byte budget, highest quality which fits is searched, 0 for none
+	MinQuality int    `mapstructure:"minQuality"` // lowest quality searched for MaxBytes
+	Watermark  string `mapstructure:"watermark"`  // name of watermark profile, empty for none
+	Resampler  string `mapstructure:"resampler"`  // resize kernel, processing.resampler when empty
+
+	Formats []string `mapstructure:"formats"` // jpeg and optionally webp, each format is stored as own variant record
+
+	Filters []string `mapstructure:"filters"` // applied after filters requested at upload
+}
+
+// WatermarkProfile describes PNG overlay applied to variants, loaded from config
+type WatermarkProfile struct {
+	Name     string  `mapstructure:"name"`
+	Source   string  `mapstructure:"source"`   // path to PNG overlay
+	Position string  `mapstructure:"position"` // top-left, top-right, bottom-left, bottom-right or center
+	Margin   int     `mapstructure:"margin"`   // pixels from image edge
+	Opacity  float64 `mapstructure:"opacity"`  // 0-1
+	Scale    float64 `mapstructure:"scale"`    // overlay width relative to image width
+}